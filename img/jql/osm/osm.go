@@ -0,0 +1,113 @@
+// Package osm (object-store mapper) converts between a
+// storage.Store's raw document tree and a typed types.Database.
+package osm
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ulmenhaus/env/img/jql/storage"
+	"github.com/ulmenhaus/env/img/jql/types"
+)
+
+// ObjectStoreMapper loads and dumps a types.Database through a
+// storage.Store, so callers never deal with the raw document tree
+// directly.
+type ObjectStoreMapper struct {
+	store storage.Store
+}
+
+// NewObjectStoreMapper returns a mapper backed by store.
+func NewObjectStoreMapper(store storage.Store) (*ObjectStoreMapper, error) {
+	if store == nil {
+		return nil, fmt.Errorf("osm requires a non-nil store")
+	}
+	return &ObjectStoreMapper{store: store}, nil
+}
+
+// Load reads a Database from r via the underlying store.
+func (m *ObjectStoreMapper) Load(r io.Reader) (*types.Database, error) {
+	raw, err := m.store.Load(r)
+	if err != nil {
+		return nil, err
+	}
+	pks, _ := m.store.(storage.PrimaryKeyReporter)
+	db := &types.Database{Tables: map[string]*types.Table{}}
+	for name, tableRaw := range raw {
+		tableMap, ok := tableRaw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("table %s is not a document map", name)
+		}
+		db.Tables[name] = tableFromRaw(tableMap, primaryKeyColumn(pks, name))
+	}
+	return db, nil
+}
+
+// primaryKeyColumn returns the primary key column pks reports for
+// table, defaulting to "id" when pks is nil or has no entry for it.
+func primaryKeyColumn(pks storage.PrimaryKeyReporter, table string) string {
+	if pks == nil {
+		return "id"
+	}
+	if pk := pks.PrimaryKeyColumns()[table]; pk != "" {
+		return pk
+	}
+	return "id"
+}
+
+// Dump writes db to w via the underlying store.
+func (m *ObjectStoreMapper) Dump(db *types.Database, w io.Writer) error {
+	raw := map[string]interface{}{}
+	for name, table := range db.Tables {
+		raw[name] = rawFromTable(table)
+	}
+	return m.store.Dump(raw, w)
+}
+
+func tableFromRaw(tableMap map[string]interface{}, pk string) *types.Table {
+	columnSeen := map[string]bool{}
+	columns := []string{}
+	entries := map[string][]types.Entry{}
+	for _, docRaw := range tableMap {
+		doc, ok := docRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for col := range doc {
+			if !columnSeen[col] {
+				columnSeen[col] = true
+				columns = append(columns, col)
+			}
+		}
+	}
+	// tableMap iteration order is randomized, so columns must be
+	// sorted before use to keep header/width order stable across
+	// loads and reloads.
+	sort.Strings(columns)
+	for key, docRaw := range tableMap {
+		doc, _ := docRaw.(map[string]interface{})
+		row := make([]types.Entry, len(columns))
+		for i, col := range columns {
+			if v, ok := doc[col]; ok && v != nil {
+				row[i] = types.NewEntry(fmt.Sprintf("%v", v))
+			} else {
+				row[i] = types.NewEntry("")
+			}
+		}
+		entries[key] = row
+	}
+	return &types.Table{Columns: columns, Entries: entries, PrimaryKeyColumn: pk}
+}
+
+func rawFromTable(table *types.Table) map[string]interface{} {
+	documents := map[string]interface{}{}
+	for key, row := range table.Entries {
+		doc := map[string]interface{}{}
+		for i, col := range table.Columns {
+			doc[col] = row[i].Format("")
+		}
+		documents[key] = doc
+	}
+	return documents
+}
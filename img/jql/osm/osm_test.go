@@ -0,0 +1,116 @@
+package osm
+
+import (
+	"io"
+	"testing"
+)
+
+// pkReportingStore is a stubStore that also reports a non-"id"
+// primary key column, to exercise osm's storage.PrimaryKeyReporter
+// handling without depending on SQLiteStore.
+type pkReportingStore struct {
+	stubStore
+	pks map[string]string
+}
+
+func (s *pkReportingStore) PrimaryKeyColumns() map[string]string {
+	return s.pks
+}
+
+// stubStore round-trips the raw document tree in memory instead of
+// serializing it, so these tests exercise osm's mapping without
+// depending on any particular storage.Store implementation.
+type stubStore struct {
+	contents map[string]interface{}
+}
+
+func (s *stubStore) Load(r io.Reader) (map[string]interface{}, error) {
+	return s.contents, nil
+}
+
+func (s *stubStore) Dump(contents map[string]interface{}, w io.Writer) error {
+	s.contents = contents
+	return nil
+}
+
+func TestObjectStoreMapperLoad(t *testing.T) {
+	store := &stubStore{contents: map[string]interface{}{
+		"people": map[string]interface{}{
+			"1": map[string]interface{}{"id": "1", "name": "alice"},
+			"2": map[string]interface{}{"id": "2", "name": "bob"},
+		},
+	}}
+	mapper, err := NewObjectStoreMapper(store)
+	if err != nil {
+		t.Fatalf("NewObjectStoreMapper: %v", err)
+	}
+	db, err := mapper.Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	table, ok := db.Tables["people"]
+	if !ok {
+		t.Fatalf("expected a people table, got %v", db.Tables)
+	}
+	if len(table.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(table.Entries))
+	}
+}
+
+func TestObjectStoreMapperDumpRoundTrip(t *testing.T) {
+	store := &stubStore{contents: map[string]interface{}{
+		"people": map[string]interface{}{
+			"1": map[string]interface{}{"id": "1", "name": "alice"},
+		},
+	}}
+	mapper, err := NewObjectStoreMapper(store)
+	if err != nil {
+		t.Fatalf("NewObjectStoreMapper: %v", err)
+	}
+	db, err := mapper.Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := mapper.Dump(db, nil); err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	reloaded, err := mapper.Load(nil)
+	if err != nil {
+		t.Fatalf("reload after dump: %v", err)
+	}
+	if len(reloaded.Tables["people"].Entries) != 1 {
+		t.Fatalf("expected dump/load round trip to preserve the one entry, got %v", reloaded.Tables["people"].Entries)
+	}
+}
+
+func TestObjectStoreMapperLoadUsesReportedPrimaryKeyColumn(t *testing.T) {
+	store := &pkReportingStore{
+		stubStore: stubStore{contents: map[string]interface{}{
+			"people": map[string]interface{}{
+				"1": map[string]interface{}{"uid": "1", "name": "alice"},
+			},
+		}},
+		pks: map[string]string{"people": "uid"},
+	}
+	mapper, err := NewObjectStoreMapper(store)
+	if err != nil {
+		t.Fatalf("NewObjectStoreMapper: %v", err)
+	}
+	db, err := mapper.Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	table, ok := db.Tables["people"]
+	if !ok {
+		t.Fatalf("expected a people table, got %v", db.Tables)
+	}
+	if table.PrimaryKeyColumn != "uid" {
+		t.Fatalf("expected PrimaryKeyColumn %q, got %q", "uid", table.PrimaryKeyColumn)
+	}
+}
+
+func TestNewObjectStoreMapperRejectsNilStore(t *testing.T) {
+	if _, err := NewObjectStoreMapper(nil); err == nil {
+		t.Fatalf("expected an error for a nil store")
+	}
+}
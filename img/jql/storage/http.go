@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// HTTPStore loads and dumps a database against a CouchDB-style
+// document endpoint: GET the base URL for the full document tree,
+// PUT a single key back to <base>/<table>/<key> to persist just that
+// document, scoped by table so two tables behind the same endpoint
+// don't collide on a shared key.
+type HTTPStore struct {
+	base string
+}
+
+// NewHTTPStore returns a Store backed by the given http(s) URL.
+func NewHTTPStore(raw string) (Store, error) {
+	return &HTTPStore{base: raw}, nil
+}
+
+// Load implements Store by GETing the base URL.
+func (s *HTTPStore) Load(r io.Reader) (map[string]interface{}, error) {
+	resp, err := http.Get(s.base)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", s.base, resp.Status)
+	}
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	contents := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &contents); err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// Dump implements Store by PUTing the entire document tree back to
+// the base URL. Prefer UpdateDocument for single-document changes.
+func (s *HTTPStore) Dump(contents map[string]interface{}, w io.Writer) error {
+	raw, err := json.Marshal(contents)
+	if err != nil {
+		return err
+	}
+	return s.put(s.base, raw)
+}
+
+// UpdateDocument implements IncrementalStore by PUTing just the
+// changed document to <base>/<table>/<key>, avoiding a full re-dump.
+func (s *HTTPStore) UpdateDocument(table, key string, doc map[string]interface{}) error {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return s.put(s.base+"/"+table+"/"+key, raw)
+}
+
+func (s *HTTPStore) put(url string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("PUT %s: %s", url, resp.Status)
+	}
+	return nil
+}
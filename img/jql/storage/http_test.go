@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPStoreLoad(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"people": {"1": {"id": "1", "name": "alice"}}}`))
+	}))
+	defer srv.Close()
+
+	s := &HTTPStore{base: srv.URL}
+	contents, err := s.Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	table, ok := contents["people"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected people table to be a map[string]interface{}, got %T", contents["people"])
+	}
+	doc, ok := table["1"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected document \"1\" to be a map[string]interface{}, got %T", table["1"])
+	}
+	if doc["name"] != "alice" {
+		t.Fatalf("doc[name] = %v, want alice", doc["name"])
+	}
+}
+
+func TestHTTPStoreLoadNonOKStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	s := &HTTPStore{base: srv.URL}
+	if _, err := s.Load(nil); err == nil {
+		t.Fatalf("expected an error for a non-200 GET")
+	}
+}
+
+func TestHTTPStoreDumpPutsToBase(t *testing.T) {
+	var gotMethod, gotPath string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotPath = r.URL.Path
+		gotBody, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	s := &HTTPStore{base: srv.URL}
+	contents := map[string]interface{}{"people": map[string]interface{}{"1": map[string]interface{}{"name": "alice"}}}
+	if err := s.Dump(contents, nil); err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	if gotMethod != http.MethodPut {
+		t.Fatalf("method = %q, want PUT", gotMethod)
+	}
+	if gotPath != "/" {
+		t.Fatalf("path = %q, want the base path", gotPath)
+	}
+	if !strings.Contains(string(gotBody), "alice") {
+		t.Fatalf("body = %s, want it to contain the dumped document", gotBody)
+	}
+}
+
+func TestHTTPStoreUpdateDocumentScopesPathByTable(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+	defer srv.Close()
+
+	s := &HTTPStore{base: srv.URL}
+	if err := s.UpdateDocument("people", "1", map[string]interface{}{"name": "alice"}); err != nil {
+		t.Fatalf("UpdateDocument: %v", err)
+	}
+	if want := "/people/1"; gotPath != want {
+		t.Fatalf("path = %q, want %q", gotPath, want)
+	}
+}
+
+func TestHTTPStorePutNonSuccessStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	s := &HTTPStore{base: srv.URL}
+	if err := s.UpdateDocument("people", "1", map[string]interface{}{}); err == nil {
+		t.Fatalf("expected an error for a non-2xx PUT response")
+	}
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+)
+
+// JSONStore loads and dumps a database as a single JSON document.
+type JSONStore struct{}
+
+// Load implements Store.
+func (s *JSONStore) Load(r io.Reader) (map[string]interface{}, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	contents := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &contents); err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// Dump implements Store.
+func (s *JSONStore) Dump(contents map[string]interface{}, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(contents)
+}
@@ -0,0 +1,63 @@
+package storage
+
+import "fmt"
+
+// Factory constructs a Store for a URL or path that matched a
+// Registry entry. raw is the unparsed path/URL so a factory can pull
+// query parameters out of it (e.g. sqlite://path.db?table=foo).
+type Factory func(raw string) (Store, error)
+
+// Registry maps file extensions and URL schemes to the Store
+// Factory that handles them, so NewMainView's dispatch is a lookup
+// rather than a growing if/else chain.
+type Registry struct {
+	byExtension map[string]Factory
+	byScheme    map[string]Factory
+}
+
+// NewRegistry returns a Registry with jql's built-in backends
+// registered.
+func NewRegistry() *Registry {
+	r := &Registry{
+		byExtension: map[string]Factory{},
+		byScheme:    map[string]Factory{},
+	}
+	r.RegisterExtension("json", func(raw string) (Store, error) { return &JSONStore{}, nil })
+	r.RegisterExtension("yaml", func(raw string) (Store, error) { return &YAMLStore{}, nil })
+	r.RegisterExtension("yml", func(raw string) (Store, error) { return &YAMLStore{}, nil })
+	r.RegisterExtension("toml", func(raw string) (Store, error) { return &TOMLStore{}, nil })
+	r.RegisterScheme("sqlite", NewSQLiteStore)
+	r.RegisterScheme("http", NewHTTPStore)
+	r.RegisterScheme("https", NewHTTPStore)
+	return r
+}
+
+// RegisterExtension registers a Factory for a file extension
+// (without the leading dot).
+func (r *Registry) RegisterExtension(ext string, f Factory) {
+	r.byExtension[ext] = f
+}
+
+// RegisterScheme registers a Factory for a URL scheme (e.g.
+// "sqlite", "http").
+func (r *Registry) RegisterScheme(scheme string, f Factory) {
+	r.byScheme[scheme] = f
+}
+
+// StoreForExtension returns the Store registered for ext, if any.
+func (r *Registry) StoreForExtension(ext, raw string) (Store, error) {
+	f, ok := r.byExtension[ext]
+	if !ok {
+		return nil, fmt.Errorf("unknown file type: %s", ext)
+	}
+	return f(raw)
+}
+
+// StoreForScheme returns the Store registered for scheme, if any.
+func (r *Registry) StoreForScheme(scheme, raw string) (Store, error) {
+	f, ok := r.byScheme[scheme]
+	if !ok {
+		return nil, fmt.Errorf("unknown URL scheme: %s", scheme)
+	}
+	return f(raw)
+}
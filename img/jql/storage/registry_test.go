@@ -0,0 +1,41 @@
+package storage
+
+import "testing"
+
+func TestRegistryStoreForExtension(t *testing.T) {
+	r := &Registry{byExtension: map[string]Factory{}, byScheme: map[string]Factory{}}
+	want := &JSONStore{}
+	r.RegisterExtension("json", func(raw string) (Store, error) { return want, nil })
+
+	got, err := r.StoreForExtension("json", "db.json")
+	if err != nil {
+		t.Fatalf("StoreForExtension: %v", err)
+	}
+	if got != Store(want) {
+		t.Fatalf("StoreForExtension returned %v, want %v", got, want)
+	}
+
+	if _, err := r.StoreForExtension("yaml", "db.yaml"); err == nil {
+		t.Fatalf("expected an error for an unregistered extension")
+	}
+}
+
+func TestRegistryStoreForScheme(t *testing.T) {
+	r := &Registry{byExtension: map[string]Factory{}, byScheme: map[string]Factory{}}
+	var gotRaw string
+	r.RegisterScheme("sqlite", func(raw string) (Store, error) {
+		gotRaw = raw
+		return nil, nil
+	})
+
+	if _, err := r.StoreForScheme("sqlite", "sqlite://db.sqlite?table=foo"); err != nil {
+		t.Fatalf("StoreForScheme: %v", err)
+	}
+	if gotRaw != "sqlite://db.sqlite?table=foo" {
+		t.Fatalf("factory received raw = %q, want the full URL", gotRaw)
+	}
+
+	if _, err := r.StoreForScheme("http", "http://example.com"); err == nil {
+		t.Fatalf("expected an error for an unregistered scheme")
+	}
+}
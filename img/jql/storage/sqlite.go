@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// IncrementalStore is implemented by stores that can persist a
+// single changed document without rewriting the whole file, so
+// saveContents can avoid a full Dump on every 's' for large tables.
+type IncrementalStore interface {
+	Store
+	// UpdateDocument writes a single document identified by table
+	// and key, without touching the rest of the store.
+	UpdateDocument(table, key string, doc map[string]interface{}) error
+}
+
+// SQLiteStore maps a single SQLite table to a jql table, with each
+// row becoming a document keyed by its primary key column.
+type SQLiteStore struct {
+	path  string
+	table string
+	pk    string
+	db    *sql.DB
+}
+
+// NewSQLiteStore parses a sqlite://path.db?table=foo&pk=bar URL and
+// opens the database. pk defaults to "id" when not given, matching
+// the common convention.
+func NewSQLiteStore(raw string) (Store, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	table := u.Query().Get("table")
+	if table == "" {
+		return nil, fmt.Errorf("sqlite store requires a ?table= parameter")
+	}
+	pk := u.Query().Get("pk")
+	if pk == "" {
+		pk = "id"
+	}
+	path := u.Opaque
+	if path == "" {
+		path = strings.TrimPrefix(u.Path, "/")
+		if u.Host != "" {
+			// sqlite://path.db puts the file name in the host part
+			path = u.Host + u.Path
+		}
+	}
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteStore{path: path, table: table, pk: pk, db: db}, nil
+}
+
+// Load implements Store by reading every row of the configured table
+// into a document keyed by its s.pk column, matching how
+// UpdateDocument and Dump key and filter rows.
+func (s *SQLiteStore) Load(r io.Reader) (map[string]interface{}, error) {
+	rows, err := s.db.Query(fmt.Sprintf("SELECT * FROM %s", s.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	pkIdx := -1
+	for i, col := range cols {
+		if col == s.pk {
+			pkIdx = i
+		}
+	}
+	if pkIdx == -1 {
+		return nil, fmt.Errorf("table %s has no column %s", s.table, s.pk)
+	}
+	documents := map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(cols))
+		pointers := make([]interface{}, len(cols))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		doc := map[string]interface{}{}
+		for i, col := range cols {
+			doc[col] = values[i]
+		}
+		key := fmt.Sprintf("%v", values[pkIdx])
+		documents[key] = doc
+	}
+	return map[string]interface{}{s.table: documents}, rows.Err()
+}
+
+// Dump implements Store by replacing every row of the configured
+// table. Prefer UpdateDocument for single-row changes.
+func (s *SQLiteStore) Dump(contents map[string]interface{}, w io.Writer) error {
+	documents, _ := contents[s.table].(map[string]interface{})
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", s.table)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for key, doc := range documents {
+		if err := insertDocument(tx, s.table, key, doc); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// PrimaryKeyColumns implements PrimaryKeyReporter by reporting the
+// configured pk column for s.table.
+func (s *SQLiteStore) PrimaryKeyColumns() map[string]string {
+	return map[string]string{s.table: s.pk}
+}
+
+// UpdateDocument implements IncrementalStore by replacing a single
+// row, so large tables don't get rewritten on every save. table is
+// ignored beyond the interface contract: a SQLiteStore always backs
+// the one table it was opened against.
+func (s *SQLiteStore) UpdateDocument(table, key string, doc map[string]interface{}) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", s.table, s.pk), key); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertDocument(tx, s.table, key, doc); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertDocument(tx *sql.Tx, table, key string, doc interface{}) error {
+	fields, ok := doc.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("document for %s is not a map", key)
+	}
+	cols := make([]string, 0, len(fields))
+	placeholders := make([]string, 0, len(fields))
+	values := make([]interface{}, 0, len(fields))
+	for col, val := range fields {
+		cols = append(cols, col)
+		placeholders = append(placeholders, "?")
+		values = append(values, val)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(cols, ", "), strings.Join(placeholders, ", "))
+	_, err := tx.Exec(query, values...)
+	return err
+}
@@ -0,0 +1,28 @@
+package storage
+
+import "io"
+
+// Store persists a database's raw document tree to and from a byte
+// stream. Each supported file format (JSON, YAML, TOML, &c) and
+// remote backend (SQLite, HTTP/CouchDB-style) implements Store and
+// registers itself with a Registry so osm.ObjectStoreMapper can load
+// and dump through it without caring about the underlying format.
+type Store interface {
+	// Load reads the store's serialized form from r into a raw
+	// document tree for osm to map onto a types.Database.
+	Load(r io.Reader) (map[string]interface{}, error)
+	// Dump writes a raw document tree, as produced by osm from a
+	// types.Database, to w in the store's serialized form.
+	Dump(contents map[string]interface{}, w io.Writer) error
+}
+
+// PrimaryKeyReporter is implemented by stores whose primary key
+// column isn't always "id" (e.g. SQLiteStore, configured via a
+// ?pk= query parameter), so osm.ObjectStoreMapper.Load can key each
+// loaded types.Table by the column the store actually keys documents
+// by instead of assuming "id".
+type PrimaryKeyReporter interface {
+	// PrimaryKeyColumns returns the primary key column name for each
+	// table the store knows about, keyed by table name.
+	PrimaryKeyColumns() map[string]string
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"io"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TOMLStore loads and dumps a database as a single TOML document.
+type TOMLStore struct{}
+
+// Load implements Store.
+func (s *TOMLStore) Load(r io.Reader) (map[string]interface{}, error) {
+	contents := map[string]interface{}{}
+	if _, err := toml.DecodeReader(r, &contents); err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// Dump implements Store.
+func (s *TOMLStore) Dump(contents map[string]interface{}, w io.Writer) error {
+	return toml.NewEncoder(w).Encode(contents)
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// YAMLStore loads and dumps a database as a single YAML document.
+type YAMLStore struct{}
+
+// Load implements Store.
+func (s *YAMLStore) Load(r io.Reader) (map[string]interface{}, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	contents := map[string]interface{}{}
+	if err := yaml.Unmarshal(raw, &contents); err != nil {
+		return nil, err
+	}
+	return normalizeYAMLMaps(contents).(map[string]interface{}), nil
+}
+
+// normalizeYAMLMaps recursively converts the map[interface{}]interface{}
+// values yaml.v2 produces for nested mappings into
+// map[string]interface{}, so a table's documents satisfy the same
+// type assertion regardless of nesting depth (osm.tableFromRaw, e.g.,
+// asserts each document is a map[string]interface{}).
+func normalizeYAMLMaps(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprintf("%v", key)] = normalizeYAMLMaps(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = normalizeYAMLMaps(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = normalizeYAMLMaps(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// Dump implements Store.
+func (s *YAMLStore) Dump(contents map[string]interface{}, w io.Writer) error {
+	raw, err := yaml.Marshal(contents)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(raw)
+	return err
+}
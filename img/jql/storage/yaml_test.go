@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestYAMLStoreLoadNormalizesNestedTables(t *testing.T) {
+	s := &YAMLStore{}
+	contents := `
+people:
+  "1":
+    id: "1"
+    name: alice
+  "2":
+    id: "2"
+    name: bob
+`
+	raw, err := s.Load(strings.NewReader(contents))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	table, ok := raw["people"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected people table to be a map[string]interface{}, got %T", raw["people"])
+	}
+	doc, ok := table["1"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected document \"1\" to be a map[string]interface{}, got %T", table["1"])
+	}
+	if doc["name"] != "alice" {
+		t.Fatalf("doc[name] = %v, want alice", doc["name"])
+	}
+}
+
+func TestYAMLStoreDumpLoadRoundTrip(t *testing.T) {
+	s := &YAMLStore{}
+	original := map[string]interface{}{
+		"people": map[string]interface{}{
+			"1": map[string]interface{}{"id": "1", "name": "alice"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := s.Dump(original, &buf); err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	reloaded, err := s.Load(&buf)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	table, ok := reloaded["people"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected people table to be a map[string]interface{}, got %T", reloaded["people"])
+	}
+	doc, ok := table["1"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected document \"1\" to be a map[string]interface{}, got %T", table["1"])
+	}
+	if doc["name"] != "alice" {
+		t.Fatalf("doc[name] = %v, want alice", doc["name"])
+	}
+}
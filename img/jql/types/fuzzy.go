@@ -0,0 +1,55 @@
+package types
+
+import "strings"
+
+// FuzzyMatchResult is the result of fuzzy-matching a query against a
+// candidate string: whether every rune matched, the positions of the
+// matched runes in candidate (used to highlight them), and a score
+// used to rank results.
+type FuzzyMatchResult struct {
+	Matched   bool
+	Positions []int
+	Score     int
+}
+
+// FuzzyMatch reports whether every rune of query appears, in order,
+// as a subsequence of candidate, and scores the match (sahilm/fuzzy
+// style: contiguous runs and early matches score higher). Used by
+// Table.Query to rank QueryParams.Filter matches, and by the ui
+// package to highlight the matched positions in TableView.
+func FuzzyMatch(query, candidate string) FuzzyMatchResult {
+	if query == "" {
+		return FuzzyMatchResult{Matched: true}
+	}
+	q := []rune(strings.ToLower(query))
+	c := []rune(strings.ToLower(candidate))
+
+	positions := make([]int, 0, len(q))
+	score := 0
+	run := 0
+	ci := 0
+	for _, qr := range q {
+		found := false
+		for ; ci < len(c); ci++ {
+			if c[ci] == qr {
+				positions = append(positions, ci)
+				if run > 0 {
+					score += run * 2
+				}
+				run++
+				score++
+				if ci < 10 {
+					score += 10 - ci
+				}
+				ci++
+				found = true
+				break
+			}
+			run = 0
+		}
+		if !found {
+			return FuzzyMatchResult{}
+		}
+	}
+	return FuzzyMatchResult{Matched: true, Positions: positions, Score: score}
+}
@@ -0,0 +1,87 @@
+package types
+
+import "testing"
+
+func TestFuzzyMatch(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		candidate string
+		wantMatch bool
+	}{
+		{"empty query matches anything", "", "anything", true},
+		{"exact match", "foo", "foo", true},
+		{"subsequence match", "fo", "foobar", true},
+		{"out of order does not match", "oof", "foo", false},
+		{"case insensitive", "FOO", "foo", true},
+		{"missing rune does not match", "fooz", "foobar", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := FuzzyMatch(c.query, c.candidate).Matched; got != c.wantMatch {
+				t.Errorf("FuzzyMatch(%q, %q).Matched = %v, want %v", c.query, c.candidate, got, c.wantMatch)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatchRanksContiguousHigher(t *testing.T) {
+	contiguous := FuzzyMatch("foo", "fooxxxxxxxx").Score
+	scattered := FuzzyMatch("foo", "fxoxoxxxxxx").Score
+	if contiguous <= scattered {
+		t.Errorf("expected contiguous match score (%d) to rank above scattered match score (%d)", contiguous, scattered)
+	}
+}
+
+func TestFuzzyMatchPositions(t *testing.T) {
+	m := FuzzyMatch("ac", "abc")
+	if !m.Matched {
+		t.Fatalf("expected a match")
+	}
+	want := []int{0, 2}
+	if len(m.Positions) != len(want) {
+		t.Fatalf("Positions = %v, want %v", m.Positions, want)
+	}
+	for i := range want {
+		if m.Positions[i] != want[i] {
+			t.Fatalf("Positions = %v, want %v", m.Positions, want)
+		}
+	}
+}
+
+func TestTableQueryFilter(t *testing.T) {
+	table := &Table{
+		Columns:          []string{"id", "name"},
+		PrimaryKeyColumn: "id",
+		Entries: map[string][]Entry{
+			"1": {NewEntry("1"), NewEntry("apple")},
+			"2": {NewEntry("2"), NewEntry("banana")},
+			"3": {NewEntry("3"), NewEntry("grape")},
+		},
+	}
+	rows, err := table.Query(QueryParams{Filter: "an"})
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if len(rows) != 1 || rows[0][1].Format("") != "banana" {
+		t.Fatalf("expected only banana to match filter %q, got %v", "an", rows)
+	}
+}
+
+func TestTableQueryOrderBy(t *testing.T) {
+	table := &Table{
+		Columns:          []string{"id", "name"},
+		PrimaryKeyColumn: "id",
+		Entries: map[string][]Entry{
+			"1": {NewEntry("1"), NewEntry("banana")},
+			"2": {NewEntry("2"), NewEntry("apple")},
+		},
+	}
+	rows, err := table.Query(QueryParams{OrderBy: "name"})
+	if err != nil {
+		t.Fatalf("Query returned error: %v", err)
+	}
+	if rows[0][1].Format("") != "apple" || rows[1][1].Format("") != "banana" {
+		t.Fatalf("expected ascending order by name, got %v", rows)
+	}
+}
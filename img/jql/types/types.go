@@ -0,0 +1,194 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Entry is a single cell value in a Table.
+type Entry struct {
+	value string
+}
+
+// NewEntry wraps a raw string as an Entry.
+func NewEntry(value string) Entry {
+	return Entry{value: value}
+}
+
+// Format renders the entry's value. formatString is reserved for
+// future column-specific formatting (dates, currency, &c) and is
+// currently unused.
+func (e Entry) Format(formatString string) string {
+	return e.value
+}
+
+// Add returns a new Entry with delta added to the current value,
+// erroring if the entry isn't numeric.
+func (e Entry) Add(delta int) (Entry, error) {
+	n, err := strconv.Atoi(e.value)
+	if err != nil {
+		return Entry{}, fmt.Errorf("cannot increment non-numeric entry %q", e.value)
+	}
+	return Entry{value: strconv.Itoa(n + delta)}, nil
+}
+
+// QueryParams configures a Table.Query call.
+type QueryParams struct {
+	// OrderBy is the column to sort by, or "" for the table's
+	// natural (primary key) order.
+	OrderBy string
+	// Dec sorts descending when true, ascending when false.
+	Dec bool
+	// Filter, when non-empty, is a fuzzy-match query applied across
+	// every visible column (those whose names don't start with "_");
+	// rows with no matching column are
+	// excluded and the remaining rows are ranked by match quality.
+	Filter string
+}
+
+// Table is a single named collection of entries, keyed by primary
+// key, with a fixed set of columns.
+type Table struct {
+	Columns []string
+	Entries map[string][]Entry
+	// PrimaryKeyColumn names the column entries are keyed by in
+	// Entries; Primary resolves it to a column index.
+	PrimaryKeyColumn string
+}
+
+// Primary returns the column index of the table's primary key.
+func (t *Table) Primary() int {
+	for i, c := range t.Columns {
+		if c == t.PrimaryKeyColumn {
+			return i
+		}
+	}
+	return 0
+}
+
+// Query returns the table's rows, filtered by params.Filter (if set)
+// and ordered by params.OrderBy (if set), else by descending match
+// score when params.Filter is set, else by primary key.
+func (t *Table) Query(params QueryParams) ([][]Entry, error) {
+	type candidate struct {
+		key   string
+		row   []Entry
+		score int
+	}
+	candidates := make([]candidate, 0, len(t.Entries))
+	for key, row := range t.Entries {
+		if params.Filter == "" {
+			candidates = append(candidates, candidate{key: key, row: row})
+			continue
+		}
+		best := -1
+		matched := false
+		for i, entry := range row {
+			if i < len(t.Columns) && strings.HasPrefix(t.Columns[i], "_") {
+				continue
+			}
+			if m := FuzzyMatch(params.Filter, entry.Format("")); m.Matched {
+				matched = true
+				if m.Score > best {
+					best = m.Score
+				}
+			}
+		}
+		if matched {
+			candidates = append(candidates, candidate{key: key, row: row, score: best})
+		}
+	}
+
+	orderIdx := -1
+	for i, c := range t.Columns {
+		if c == params.OrderBy {
+			orderIdx = i
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		switch {
+		case orderIdx >= 0:
+			vi, vj := candidates[i].row[orderIdx].Format(""), candidates[j].row[orderIdx].Format("")
+			cmp := compareValues(vi, vj)
+			if params.Dec {
+				return cmp > 0
+			}
+			return cmp < 0
+		case params.Filter != "":
+			if candidates[i].score != candidates[j].score {
+				return candidates[i].score > candidates[j].score
+			}
+			return candidates[i].key < candidates[j].key
+		default:
+			return candidates[i].key < candidates[j].key
+		}
+	})
+
+	rows := make([][]Entry, len(candidates))
+	for i, c := range candidates {
+		rows[i] = c.row
+	}
+	return rows, nil
+}
+
+// compareValues orders two formatted column values numerically when
+// both parse as numbers, falling back to a string comparison
+// otherwise, so that e.g. "9" sorts before "10".
+func compareValues(a, b string) int {
+	if af, aerr := strconv.ParseFloat(a, 64); aerr == nil {
+		if bf, berr := strconv.ParseFloat(b, 64); berr == nil {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// Update sets the value of column for the entry keyed by key.
+func (t *Table) Update(key, column, contents string) error {
+	row, ok := t.Entries[key]
+	if !ok {
+		return fmt.Errorf("no such entry: %s", key)
+	}
+	for i, c := range t.Columns {
+		if c == column {
+			row[i] = NewEntry(contents)
+			return nil
+		}
+	}
+	return fmt.Errorf("no such column: %s", column)
+}
+
+// Insert adds a new, empty entry keyed by pk.
+func (t *Table) Insert(pk string) error {
+	if _, ok := t.Entries[pk]; ok {
+		return fmt.Errorf("entry already exists: %s", pk)
+	}
+	row := make([]Entry, len(t.Columns))
+	primary := t.Primary()
+	row[primary] = NewEntry(pk)
+	t.Entries[pk] = row
+	return nil
+}
+
+// Delete removes the entry keyed by key.
+func (t *Table) Delete(key string) error {
+	if _, ok := t.Entries[key]; !ok {
+		return fmt.Errorf("no such entry: %s", key)
+	}
+	delete(t.Entries, key)
+	return nil
+}
+
+// Database is a named collection of Tables.
+type Database struct {
+	Tables map[string]*Table
+}
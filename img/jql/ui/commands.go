@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// CommandHandler implements the behavior of a single registered
+// command. args excludes the command name itself.
+type CommandHandler func(mv *MainView, args []string) error
+
+// Command is a single entry in a CommandRegistry: a name, its
+// argument spec (for :help), and the handler invoked with the
+// remaining prompt tokens.
+type Command struct {
+	Name    string
+	Args    string // e.g. "<table>" or "<col> [asc|desc]", empty if none
+	Help    string
+	Handler CommandHandler
+}
+
+// CommandRegistry maps command-mode names to their Command, so that
+// promptExit's MainViewModePrompt branch is a lookup rather than a
+// switch that grows with every new command.
+type CommandRegistry struct {
+	commands map[string]Command
+}
+
+// NewCommandRegistry returns a CommandRegistry with jql's built-in
+// commands registered.
+func NewCommandRegistry() *CommandRegistry {
+	r := &CommandRegistry{commands: map[string]Command{}}
+	r.Register(Command{
+		Name: "create-new-entry",
+		Args: "<pk>",
+		Help: "Create a new entry with the given primary key",
+		Handler: func(mv *MainView, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("create-new-entry takes 1 arg")
+			}
+			return mv.doCommand(&insertEntryCommand{mv: mv, pk: args[0]})
+		},
+	})
+	r.Register(Command{
+		Name: "delete",
+		Args: "<pk>",
+		Help: "Delete the entry with the given primary key",
+		Handler: func(mv *MainView, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("delete takes 1 arg")
+			}
+			return mv.doCommand(&deleteEntryCommand{mv: mv, pk: args[0]})
+		},
+	})
+	r.Register(Command{
+		Name: "source",
+		Args: "<file>",
+		Help: "Run each line of the given file as a command",
+		Handler: func(mv *MainView, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("source takes 1 arg")
+			}
+			contents, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			for _, line := range strings.Split(string(contents), "\n") {
+				line = strings.TrimSpace(line)
+				if line == "" {
+					continue
+				}
+				if err := mv.runCommandLine(line); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+	r.Register(Command{
+		Name: "goto",
+		Args: "<table>",
+		Help: "Switch the main view to the named table",
+		Handler: func(mv *MainView, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("goto takes 1 arg")
+			}
+			return mv.loadTable(args[0])
+		},
+	})
+	r.Register(Command{
+		Name: "filter",
+		Args: "<expr>",
+		Help: "Apply a fuzzy-match filter to the current table",
+		Handler: func(mv *MainView, args []string) error {
+			mv.filter = strings.Join(args, " ")
+			return mv.updateTableViewContents()
+		},
+	})
+	r.Register(Command{
+		Name: "sort",
+		Args: "<col> [asc|desc]",
+		Help: "Order the current table by a column",
+		Handler: func(mv *MainView, args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return fmt.Errorf("sort takes 1 or 2 args")
+			}
+			mv.Params.OrderBy = args[0]
+			mv.Params.Dec = len(args) == 2 && args[1] == "desc"
+			return mv.updateTableViewContents()
+		},
+	})
+	r.Register(Command{
+		Name: "export",
+		Args: "<path>",
+		Help: "Write the current contents to the given path",
+		Handler: func(mv *MainView, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("export takes 1 arg")
+			}
+			return mv.exportContents(args[0])
+		},
+	})
+	r.Register(Command{
+		Name: "set",
+		Args: "<col> <value>",
+		Help: "Set column to value for every marked row (select mode)",
+		Handler: func(mv *MainView, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("set takes a column and a value")
+			}
+			cmd, err := mv.bulkSetColumn(args[0], strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+			return mv.doCommand(cmd)
+		},
+	})
+	r.Register(Command{
+		Name: "bump",
+		Args: "<col> <delta>",
+		Help: "Add delta to column for every marked row (select mode)",
+		Handler: func(mv *MainView, args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf("bump takes a column and a delta")
+			}
+			var delta int
+			if _, err := fmt.Sscanf(args[1], "%d", &delta); err != nil {
+				return fmt.Errorf("invalid delta: %s", args[1])
+			}
+			cmd, err := mv.bulkBumpColumn(args[0], delta)
+			if err != nil {
+				return err
+			}
+			return mv.doCommand(cmd)
+		},
+	})
+	r.Register(Command{
+		Name: "reload",
+		Help: "Reload the database from disk, reconciling unsaved edits",
+		Handler: func(mv *MainView, args []string) error {
+			return mv.reload()
+		},
+	})
+	r.Register(Command{
+		Name: "help",
+		Help: "List all registered commands",
+		Handler: func(mv *MainView, args []string) error {
+			mv.toggleHelp()
+			return nil
+		},
+	})
+	return r
+}
+
+// Register adds or overwrites a command by name.
+func (r *CommandRegistry) Register(c Command) {
+	r.commands[c.Name] = c
+}
+
+// Lookup returns the command registered under name.
+func (r *CommandRegistry) Lookup(name string) (Command, bool) {
+	c, ok := r.commands[name]
+	return c, ok
+}
+
+// Complete returns the registered command names with the given
+// prefix, sorted, for tab-completion in the prompt.
+func (r *CommandRegistry) Complete(prefix string) []string {
+	matches := []string{}
+	for name := range r.commands {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// Names returns every registered command name, sorted.
+func (r *CommandRegistry) Names() []string {
+	return r.Complete("")
+}
@@ -0,0 +1,32 @@
+package ui
+
+import "testing"
+
+func TestCommandRegistryLookup(t *testing.T) {
+	r := NewCommandRegistry()
+	if _, ok := r.Lookup("goto"); !ok {
+		t.Fatalf("expected goto to be registered")
+	}
+	if _, ok := r.Lookup("nonexistent-command"); ok {
+		t.Fatalf("expected nonexistent-command to be unregistered")
+	}
+}
+
+func TestCommandRegistryComplete(t *testing.T) {
+	r := &CommandRegistry{commands: map[string]Command{
+		"goto":   {Name: "goto"},
+		"export": {Name: "export"},
+		"filter": {Name: "filter"},
+	}}
+	matches := r.Complete("go")
+	if len(matches) != 1 || matches[0] != "goto" {
+		t.Fatalf("Complete(%q) = %v, want [goto]", "go", matches)
+	}
+	matches = r.Complete("")
+	if len(matches) != 3 {
+		t.Fatalf("Complete(%q) = %v, want 3 matches", "", matches)
+	}
+	if matches[0] != "export" || matches[1] != "filter" || matches[2] != "goto" {
+		t.Fatalf("Complete(%q) = %v, want sorted order", "", matches)
+	}
+}
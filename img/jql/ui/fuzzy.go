@@ -0,0 +1,16 @@
+package ui
+
+import "github.com/ulmenhaus/env/img/jql/types"
+
+// fuzzyMatch is a single fuzzy-match result against a candidate
+// string: the positions of matched runes (for highlighting) and a
+// score used to rank results.
+type fuzzyMatch = types.FuzzyMatchResult
+
+// fuzzyScore scores candidate against query, delegating to
+// types.FuzzyMatch so table ranking (Table.Query) and highlight
+// positions (TableView.WriteContents) share one matching algorithm
+// instead of two copies.
+func fuzzyScore(query, candidate string) fuzzyMatch {
+	return types.FuzzyMatch(query, candidate)
+}
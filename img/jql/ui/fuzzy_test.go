@@ -0,0 +1,45 @@
+package ui
+
+import "testing"
+
+func TestFuzzyScoreSubsequence(t *testing.T) {
+	cases := []struct {
+		query, candidate string
+		want             bool
+	}{
+		{"abc", "alphabetcat", true},
+		{"abc", "cab", false},
+		{"", "anything", true},
+		{"xyz", "abc", false},
+	}
+	for _, c := range cases {
+		got := fuzzyScore(c.query, c.candidate).Matched
+		if got != c.want {
+			t.Errorf("fuzzyScore(%q, %q).Matched = %v, want %v", c.query, c.candidate, got, c.want)
+		}
+	}
+}
+
+func TestFuzzyScorePositions(t *testing.T) {
+	m := fuzzyScore("ac", "abc")
+	if !m.Matched {
+		t.Fatalf("expected a match")
+	}
+	want := []int{0, 2}
+	if len(m.Positions) != len(want) {
+		t.Fatalf("Positions = %v, want %v", m.Positions, want)
+	}
+	for i := range want {
+		if m.Positions[i] != want[i] {
+			t.Fatalf("Positions = %v, want %v", m.Positions, want)
+		}
+	}
+}
+
+func TestFuzzyScoreRanksContiguousHigher(t *testing.T) {
+	contiguous := fuzzyScore("cat", "catalog")
+	scattered := fuzzyScore("cat", "chased a tiger")
+	if contiguous.Score <= scattered.Score {
+		t.Fatalf("expected a contiguous match to outscore a scattered one: %d <= %d", contiguous.Score, scattered.Score)
+	}
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/jroimartin/gocui"
+)
+
+// writeHelp renders every binding in keyMap grouped by mode, with
+// its short help label and longer description, to the given view.
+func writeHelp(v *gocui.View, keyMaps []KeyMap) {
+	for _, km := range keyMaps {
+		fmt.Fprintf(v, "%s\n", modeName(km.Mode))
+		for _, b := range km.Bindings {
+			fmt.Fprintf(v, "  %-14s %-16s %s\n", keyLabel(b), b.Help, b.Description)
+		}
+		fmt.Fprintln(v)
+	}
+}
+
+// writeCommands renders every command registered in r, for the
+// :help command.
+func writeCommands(v *gocui.View, r *CommandRegistry) {
+	fmt.Fprintf(v, "Commands\n")
+	for _, name := range r.Names() {
+		cmd, _ := r.Lookup(name)
+		fmt.Fprintf(v, "  %-14s %-20s %s\n", cmd.Name, cmd.Args, cmd.Help)
+	}
+}
+
+func keyLabel(b Binding) string {
+	if len(b.Keys) > 0 {
+		return string(b.Keys)
+	}
+	switch b.Key {
+	case gocui.KeyArrowRight:
+		return "<Right>"
+	case gocui.KeyArrowLeft:
+		return "<Left>"
+	case gocui.KeyArrowUp:
+		return "<Up>"
+	case gocui.KeyArrowDown:
+		return "<Down>"
+	case gocui.KeyEnter:
+		return "<Enter>"
+	case gocui.KeyEsc:
+		return "<Esc>"
+	case gocui.KeyCtrlR:
+		return "<C-r>"
+	case gocui.KeyTab:
+		return "<Tab>"
+	default:
+		return "?"
+	}
+}
+
+func modeName(m MainViewMode) string {
+	switch m {
+	case MainViewModeTable:
+		return "Table"
+	case MainViewModePrompt:
+		return "Prompt"
+	case MainViewModeEdit:
+		return "Edit"
+	case MainViewModeFilter:
+		return "Filter"
+	case MainViewModeSelect:
+		return "Select"
+	default:
+		return "Other"
+	}
+}
@@ -0,0 +1,13 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/jroimartin/gocui"
+)
+
+func TestKeyLabelRendersTab(t *testing.T) {
+	if got := keyLabel(Binding{Key: gocui.KeyTab}); got != "<Tab>" {
+		t.Fatalf("keyLabel(Tab) = %q, want %q", got, "<Tab>")
+	}
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"os/exec"
+
+	"github.com/jroimartin/gocui"
+)
+
+// Binding describes a single keyboard shortcut: the keys that
+// trigger it and the help text shown for it in the help overlay.
+type Binding struct {
+	// Keys are the runes that trigger the binding in table mode.
+	// A binding may also be triggered by Key below.
+	Keys []rune
+	// Key is a non-printable gocui key (e.g. an arrow key) that
+	// triggers the binding, if any.
+	Key gocui.Key
+	// Help is the short label shown alongside the key in the help
+	// overlay (e.g. "filter").
+	Help string
+	// Description is the longer, one-sentence explanation shown
+	// when the help overlay is expanded.
+	Description string
+
+	handler func(mv *MainView) error
+}
+
+// KeyMap is an ordered set of bindings for a single mode (Table,
+// Prompt, Edit, &c). Bindings are matched in order, so a table's
+// config can prepend overrides that take precedence over defaults.
+type KeyMap struct {
+	Mode     MainViewMode
+	Bindings []Binding
+}
+
+// Lookup returns the first binding matching the given key/rune, and
+// whether one was found.
+func (k KeyMap) Lookup(key gocui.Key, ch rune) (Binding, bool) {
+	for _, b := range k.Bindings {
+		if ch != 0 {
+			for _, r := range b.Keys {
+				if r == ch {
+					return b, true
+				}
+			}
+		} else if b.Key == key {
+			return b, true
+		}
+	}
+	return Binding{}, false
+}
+
+// defaultTableKeyMap is the set of bindings available in
+// MainViewModeTable. It exists so that key dispatch in Edit is a map
+// lookup rather than a switch that grows without bound; per-table
+// config can override entries by prepending to Bindings.
+func defaultTableKeyMap() KeyMap {
+	return KeyMap{
+		Mode: MainViewModeTable,
+		Bindings: []Binding{
+			{Key: gocui.KeyArrowRight, Help: "right", Description: "Move the cursor one column right",
+				handler: func(mv *MainView) error { mv.TableView.Move(DirectionRight); return nil }},
+			{Key: gocui.KeyArrowUp, Help: "up", Description: "Move the cursor one row up",
+				handler: func(mv *MainView) error { mv.TableView.Move(DirectionUp); return nil }},
+			{Key: gocui.KeyArrowLeft, Help: "left", Description: "Move the cursor one column left",
+				handler: func(mv *MainView) error { mv.TableView.Move(DirectionLeft); return nil }},
+			{Key: gocui.KeyArrowDown, Help: "down", Description: "Move the cursor one row down",
+				handler: func(mv *MainView) error { mv.TableView.Move(DirectionDown); return nil }},
+			{Key: gocui.KeyEnter, Help: "edit", Description: "Edit the value of the selected cell",
+				handler: func(mv *MainView) error {
+					mv.switchMode(MainViewModeEdit)
+					row, column := mv.TableView.GetSelected()
+					mv.promptText = mv.TableView.Values[row][column]
+					return nil
+				}},
+			{Key: gocui.KeyEsc, Help: "clear filter", Description: "Clear the active fuzzy filter",
+				handler: func(mv *MainView) error {
+					if mv.filter == "" {
+						return nil
+					}
+					mv.filter = ""
+					return mv.updateTableViewContents()
+				}},
+			{Keys: []rune{'b'}, Help: "browse", Description: "Open the selected cell's value with the OS opener",
+				handler: func(mv *MainView) error {
+					row, column := mv.TableView.GetSelected()
+					_, err := exec.Command("open", mv.TableView.Values[row][column]).CombinedOutput()
+					return err
+				}},
+			{Keys: []rune{':'}, Help: "command", Description: "Enter command mode",
+				handler: func(mv *MainView) error { mv.switchMode(MainViewModePrompt); return nil }},
+			{Keys: []rune{'/'}, Help: "filter", Description: "Filter the table by fuzzy-matching visible columns",
+				handler: func(mv *MainView) error {
+					mv.preEditFilter = mv.filter
+					mv.promptText = mv.filter
+					mv.switchMode(MainViewModeFilter)
+					return nil
+				}},
+			{Keys: []rune{'o'}, Help: "order by", Description: "Sort ascending by the selected column",
+				handler: func(mv *MainView) error {
+					_, col := mv.TableView.GetSelected()
+					mv.Params.OrderBy = mv.columns[col]
+					mv.Params.Dec = false
+					return mv.updateTableViewContents()
+				}},
+			{Keys: []rune{'O'}, Help: "order by desc", Description: "Sort descending by the selected column",
+				handler: func(mv *MainView) error {
+					_, col := mv.TableView.GetSelected()
+					mv.Params.OrderBy = mv.columns[col]
+					mv.Params.Dec = true
+					return mv.updateTableViewContents()
+				}},
+			{Keys: []rune{'i'}, Help: "increment", Description: "Increment the selected cell by one",
+				handler: func(mv *MainView) error { return mv.bumpSelected(1) }},
+			{Keys: []rune{'I'}, Help: "decrement", Description: "Decrement the selected cell by one",
+				handler: func(mv *MainView) error { return mv.bumpSelected(-1) }},
+			{Keys: []rune{'s'}, Help: "save", Description: "Write the current contents to disk",
+				handler: func(mv *MainView) error { return mv.saveContents() }},
+			{Keys: []rune{'u'}, Help: "undo", Description: "Undo the last mutation",
+				handler: func(mv *MainView) error {
+					if err := mv.undoStack.undo(); err != nil {
+						return err
+					}
+					return mv.updateTableViewContents()
+				}},
+			{Key: gocui.KeyCtrlR, Help: "redo", Description: "Redo the last undone mutation",
+				handler: func(mv *MainView) error {
+					if err := mv.undoStack.redo(); err != nil {
+						return err
+					}
+					return mv.updateTableViewContents()
+				}},
+			{Keys: []rune{'n'}, Help: "new entry", Description: "Create a new entry",
+				handler: func(mv *MainView) error { mv.newEntry(); return nil }},
+			{Keys: []rune{'?'}, Help: "help", Description: "Toggle this help overlay",
+				handler: func(mv *MainView) error { mv.toggleHelp(); return nil }},
+			{Keys: []rune{'V'}, Help: "select", Description: "Enter select mode to mark rows for a bulk action",
+				handler: func(mv *MainView) error { mv.enterSelectMode(); return nil }},
+		},
+	}
+}
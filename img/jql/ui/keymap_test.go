@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSetKeyOverridesTakesPrecedenceOverDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice"}}}`)
+
+	mv, err := NewMainView(path, "people")
+	if err != nil {
+		t.Fatalf("NewMainView: %v", err)
+	}
+
+	saved := false
+	mv.SetKeyOverrides("people", []Binding{
+		{Keys: []rune{'s'}, Help: "custom save", Description: "overridden",
+			handler: func(mv *MainView) error { saved = true; return nil }},
+	})
+	if err := mv.loadTable("people"); err != nil {
+		t.Fatalf("loadTable: %v", err)
+	}
+
+	binding, ok := mv.keyMap.Lookup(0, 's')
+	if !ok {
+		t.Fatalf("expected 's' to resolve to a binding")
+	}
+	if err := binding.handler(mv); err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+	if !saved {
+		t.Fatalf("expected the table's override to win over the default 's' (save) binding")
+	}
+}
+
+func TestLoadTableWithNoOverridesUsesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice"}}}`)
+
+	mv, err := NewMainView(path, "people")
+	if err != nil {
+		t.Fatalf("NewMainView: %v", err)
+	}
+
+	binding, ok := mv.keyMap.Lookup(0, 's')
+	if !ok {
+		t.Fatalf("expected 's' to resolve to the default save binding")
+	}
+	if binding.Help != "save" {
+		t.Fatalf("Help = %q, want %q", binding.Help, "save")
+	}
+}
@@ -2,8 +2,11 @@ package ui
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/jroimartin/gocui"
@@ -29,13 +32,35 @@ const (
 	// MainViewModeEdit is for when the user is editing
 	// the value of a single cell
 	MainViewModeEdit
+	// MainViewModeFilter is for when the user is typing a
+	// fuzzy-match filter to apply to the current table
+	MainViewModeFilter
+	// MainViewModeHelp is for when the full-screen keybinding help
+	// overlay is shown
+	MainViewModeHelp
+	// MainViewModeSelect is for when the user is marking rows to
+	// apply a bulk action to
+	MainViewModeSelect
 )
 
 // A MainView is the overall view of the table including headers,
 // prompts, &c. It will also be responsible for managing differnt
 // interaction modes if jql supports those.
 type MainView struct {
-	path string
+	path  string
+	store storage.Store
+	// fileBacked is true when store reads from/writes to a local
+	// file at path, and false for remote/DB-backed stores (sqlite://,
+	// http(s)://) that persist through mv.store directly
+	fileBacked bool
+	// tableName is the name of the currently loaded table, i.e. the
+	// key into DB.Tables for mv.Table
+	tableName string
+	// dirty tracks primary keys changed since the last save, scoped
+	// by table name so a PK that happens to collide across tables
+	// isn't misattributed after a :goto, so an IncrementalStore can
+	// persist just the changed rows instead of the whole table
+	dirty map[string]map[string]bool
 
 	OSM     *osm.ObjectStoreMapper
 	DB      *types.Database
@@ -52,37 +77,153 @@ type MainView struct {
 	switching  bool // on when transitioning modes has not yet been acknowleged by Layout
 	alert      string
 	promptText string
+
+	// filter is the current fuzzy-match query applied to the
+	// table's visible columns
+	filter string
+	// preEditFilter is filter's value from before the current filter
+	// prompt was opened, restored if the edit is canceled with Esc
+	preEditFilter string
+
+	// keyMap holds the bindings active in MainViewModeTable for the
+	// current table. It is rebuilt by loadTable on every table switch
+	// from defaultTableKeyMap with that table's keyOverrides, if any,
+	// prepended.
+	keyMap KeyMap
+	// keyOverrides maps a table name to Bindings that take
+	// precedence, via KeyMap.Lookup's first-match order, over
+	// defaultTableKeyMap's entries when that table is loaded. Set
+	// with SetKeyOverrides.
+	keyOverrides map[string][]Binding
+
+	// commands holds the registered command-mode commands
+	commands *CommandRegistry
+	// history holds previously entered command-mode lines, most
+	// recent last; PromptHandler walks it on Up/Down
+	history []string
+	// bulkPrompt is set while MainViewModePrompt was entered via the
+	// select-mode 'b' bulk menu, so promptExit routes the submitted
+	// line through runBulk instead of the ordinary command dispatch
+	bulkPrompt bool
+
+	// undoStack records mutating Commands so they can be undone
+	// with 'u' and redone with Ctrl-R
+	undoStack undoStack
+
+	// AutoMergeNonConflicting, when set, makes reload() apply
+	// external changes to rows that also have an unsaved local edit
+	// instead of alerting the user about the conflict. Rows with no
+	// local edit are always merged regardless of this setting.
+	AutoMergeNonConflicting bool
+
+	// selection holds the marked rows while in MainViewModeSelect
+	selection selectState
+}
+
+// storeAndReader resolves path (a local file path, or a jql://,
+// sqlite://, or http(s):// URL) to the Store that handles it and,
+// for local files, the reader to load from. Remote stores fetch
+// their own contents, so the returned reader is nil for them.
+func storeAndReader(path string) (storage.Store, io.Reader, error) {
+	registry := storage.NewRegistry()
+	if u, err := url.Parse(path); err == nil && u.Scheme != "" {
+		switch u.Scheme {
+		case "sqlite", "http", "https":
+			store, err := registry.StoreForScheme(u.Scheme, path)
+			return store, nil, err
+		case "jql":
+			path = strings.TrimPrefix(path, "jql://")
+		default:
+			return nil, nil, fmt.Errorf("unknown URL scheme: %s", u.Scheme)
+		}
+	}
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	store, err := registry.StoreForExtension(ext, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, f, nil
 }
 
 // NewMainView returns a MainView initialized with a given Table
 func NewMainView(path, start string) (*MainView, error) {
-	var store storage.Store
-	if strings.HasSuffix(path, ".json") {
-		store = &storage.JSONStore{}
-	} else {
-		return nil, fmt.Errorf("unknown file type")
-	}
-	mapper, err := osm.NewObjectStoreMapper(store)
+	store, r, err := storeAndReader(path)
 	if err != nil {
 		return nil, err
 	}
-	f, err := os.Open(path)
+	f, fileBacked := r.(*os.File)
+	if fileBacked {
+		defer f.Close()
+	}
+	mapper, err := osm.NewObjectStoreMapper(store)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
-	db, err := mapper.Load(f)
+	db, err := mapper.Load(r)
 	if err != nil {
 		return nil, err
 	}
 	mv := &MainView{
-		path: path,
-		OSM:  mapper,
-		DB:   db,
+		path:       path,
+		store:      store,
+		fileBacked: fileBacked,
+		dirty:      map[string]map[string]bool{},
+		OSM:        mapper,
+		DB:         db,
+		commands:   NewCommandRegistry(),
 	}
 	return mv, mv.loadTable(start)
 }
 
+// SetKeyOverrides registers Bindings that take precedence, for the
+// named table only, over defaultTableKeyMap's entries — e.g. so a
+// table's config can rebind 's' to something other than save.
+// Overrides take effect next time the table is loaded (including via
+// the "goto" command), since loadTable rebuilds mv.keyMap from them.
+func (mv *MainView) SetKeyOverrides(table string, bindings []Binding) {
+	if mv.keyOverrides == nil {
+		mv.keyOverrides = map[string][]Binding{}
+	}
+	mv.keyOverrides[table] = bindings
+}
+
+// Run creates a gocui.Gui backed by mv, starts (for a file-backed
+// store) the background file watcher from WatchForChanges so external
+// edits to mv.path are picked up live, and blocks running the UI's main loop until the
+// user quits with Ctrl-C.
+func (mv *MainView) Run() error {
+	g, err := gocui.NewGui(gocui.OutputNormal)
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+	g.SetManager(mv)
+
+	quit := func(g *gocui.Gui, v *gocui.View) error {
+		return gocui.ErrQuit
+	}
+	if err := g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	if mv.fileBacked {
+		if err := mv.WatchForChanges(g, done); err != nil {
+			return err
+		}
+	}
+
+	if err := g.MainLoop(); err != nil && err != gocui.ErrQuit {
+		return err
+	}
+	return nil
+}
+
 // loadTable display's the named table in the main table view
 func (mv *MainView) loadTable(t string) error {
 	table, ok := mv.DB.Tables[t]
@@ -90,6 +231,12 @@ func (mv *MainView) loadTable(t string) error {
 		return fmt.Errorf("unknown table: %s", t)
 	}
 	mv.Table = table
+	mv.tableName = t
+	base := defaultTableKeyMap()
+	mv.keyMap = KeyMap{
+		Mode:     base.Mode,
+		Bindings: append(append([]Binding{}, mv.keyOverrides[t]...), base.Bindings...),
+	}
 	columns := []string{}
 	widths := []int{}
 	for _, column := range table.Columns {
@@ -101,8 +248,10 @@ func (mv *MainView) loadTable(t string) error {
 		columns = append(columns, column)
 	}
 	mv.TableView = &TableView{
-		Values: [][]string{},
-		Widths: widths,
+		Values:     [][]string{},
+		Widths:     widths,
+		Highlights: [][][]int{},
+		Selected:   map[int]bool{},
 	}
 	mv.columns = columns
 	return mv.updateTableViewContents()
@@ -133,15 +282,13 @@ func (mv *MainView) Layout(g *gocui.Gui) error {
 			return err
 		}
 		prompt.Editable = true
-		prompt.Editor = &PromptHandler{
-			Callback: mv.promptExit,
-		}
+		prompt.Editor = NewPromptHandler(mv)
 	}
 	if switching {
 		prompt.Clear()
 	}
 	switch mv.Mode {
-	case MainViewModeTable:
+	case MainViewModeTable, MainViewModeSelect:
 		if _, err := g.SetCurrentView("table"); err != nil {
 			return err
 		}
@@ -152,7 +299,7 @@ func (mv *MainView) Layout(g *gocui.Gui) error {
 		}
 		g.Cursor = false
 		fmt.Fprintf(prompt, mv.alert)
-	case MainViewModePrompt:
+	case MainViewModePrompt, MainViewModeFilter:
 		if _, err := g.SetCurrentView("prompt"); err != nil {
 			return err
 		}
@@ -168,6 +315,25 @@ func (mv *MainView) Layout(g *gocui.Gui) error {
 		prompt.Write([]byte(mv.promptText))
 		prompt.MoveCursor(len(mv.promptText), 0, true)
 		mv.promptText = ""
+	case MainViewModeHelp:
+		help, err := g.SetView("help", 0, 0, maxX-2, maxY-1)
+		if err != nil {
+			if err != gocui.ErrUnknownView {
+				return err
+			}
+			help.Editable = true
+			help.Editor = mv
+		}
+		help.Clear()
+		writeHelp(help, []KeyMap{mv.keyMap, selectKeyMap(), promptKeyMap(), editKeyMap()})
+		writeCommands(help, mv.commands)
+		if _, err := g.SetCurrentView("help"); err != nil {
+			return err
+		}
+		g.Cursor = false
+	}
+	if mv.Mode != MainViewModeHelp {
+		g.DeleteView("help")
 	}
 	return nil
 }
@@ -187,8 +353,65 @@ func (mv *MainView) switchMode(new MainViewMode) {
 	mv.Mode = new
 }
 
-// saveContents asks the osm to save the current contents to disk
+// toggleHelp shows the help overlay, or returns to table mode if it
+// is already showing
+func (mv *MainView) toggleHelp() {
+	if mv.Mode == MainViewModeHelp {
+		mv.switchMode(MainViewModeTable)
+		return
+	}
+	mv.switchMode(MainViewModeHelp)
+}
+
+// bumpSelected adds delta to the currently selected cell, as used by
+// the increment/decrement bindings
+func (mv *MainView) bumpSelected(delta int) error {
+	row, col := mv.TableView.GetSelected()
+	primary := mv.Table.Primary()
+	key := mv.entries[row][primary].Format("")
+	before := mv.Table.Entries[key][col]
+	after, err := before.Add(delta)
+	if err != nil {
+		return err
+	}
+	return mv.doCommand(&updateCellCommand{
+		mv:     mv,
+		key:    key,
+		column: mv.Table.Columns[col],
+		before: before.Format(""),
+		after:  after.Format(""),
+	})
+}
+
+// saveContents asks the osm to save the current contents to disk, or
+// to mv.store directly for a remote/DB-backed store
 func (mv *MainView) saveContents() error {
+	tableDirty := mv.dirty[mv.tableName]
+	inc, incremental := mv.store.(storage.IncrementalStore)
+	for key := range tableDirty {
+		if _, ok := mv.Table.Entries[key]; !ok {
+			// a deleted row has no document to update, so fall back
+			// to a full dump
+			incremental = false
+		}
+	}
+	if incremental && len(tableDirty) > 0 {
+		count := len(tableDirty)
+		for key := range tableDirty {
+			if err := inc.UpdateDocument(mv.tableName, key, mv.documentFor(key)); err != nil {
+				return err
+			}
+			delete(tableDirty, key)
+		}
+		return fmt.Errorf("Wrote %d row(s) to %s", count, mv.path)
+	}
+	if !mv.fileBacked {
+		if err := mv.OSM.Dump(mv.DB, ioutil.Discard); err != nil {
+			return err
+		}
+		mv.dirty = map[string]map[string]bool{}
+		return fmt.Errorf("Wrote %s", mv.path)
+	}
 	f, err := os.OpenFile(mv.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
@@ -198,14 +421,55 @@ func (mv *MainView) saveContents() error {
 	if err != nil {
 		return err
 	}
+	mv.dirty = map[string]map[string]bool{}
 	return fmt.Errorf("Wrote %s", mv.path)
 }
 
-// Edit handles keyboard inputs while in table mode
+// documentFor builds the raw column->value document for a row in the
+// current table, for IncrementalStore.UpdateDocument
+func (mv *MainView) documentFor(key string) map[string]interface{} {
+	doc := map[string]interface{}{}
+	for i, col := range mv.Table.Columns {
+		doc[col] = mv.Table.Entries[key][i].Format("")
+	}
+	return doc
+}
+
+// markDirty records that the entry for key in the current table has
+// changed since the last save
+func (mv *MainView) markDirty(key string) {
+	if mv.dirty[mv.tableName] == nil {
+		mv.dirty[mv.tableName] = map[string]bool{}
+	}
+	mv.dirty[mv.tableName][key] = true
+}
+
+// exportContents asks the osm to dump the current contents to the
+// given path, leaving mv.path untouched
+func (mv *MainView) exportContents(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = mv.OSM.Dump(mv.DB, f)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("Wrote %s", path)
+}
+
+// Edit handles keyboard inputs while in table mode. Dispatch is a
+// lookup into mv.keyMap rather than a switch, so new commands don't
+// require growing this function.
 func (mv *MainView) Edit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
 	if mv.Mode == MainViewModeAlert {
 		mv.switchMode(MainViewModeTable)
 	}
+	if mv.Mode == MainViewModeHelp {
+		mv.switchMode(MainViewModeTable)
+		return
+	}
 
 	var err error
 	defer func() {
@@ -215,63 +479,12 @@ func (mv *MainView) Edit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifi
 		}
 	}()
 
-	switch key {
-	case gocui.KeyArrowRight:
-		mv.TableView.Move(DirectionRight)
-	case gocui.KeyArrowUp:
-		mv.TableView.Move(DirectionUp)
-	case gocui.KeyArrowLeft:
-		mv.TableView.Move(DirectionLeft)
-	case gocui.KeyArrowDown:
-		mv.TableView.Move(DirectionDown)
-	case gocui.KeyEnter:
-		mv.switchMode(MainViewModeEdit)
-		row, column := mv.TableView.GetSelected()
-		mv.promptText = mv.TableView.Values[row][column]
+	active := mv.keyMap
+	if mv.Mode == MainViewModeSelect {
+		active = selectKeyMap()
 	}
-
-	primary := mv.Table.Primary()
-
-	switch ch {
-	case 'b':
-		row, column := mv.TableView.GetSelected()
-		_, err = exec.Command("open", mv.TableView.Values[row][column]).CombinedOutput()
-	case ':':
-		mv.switchMode(MainViewModePrompt)
-	case 'o':
-		_, col := mv.TableView.GetSelected()
-		mv.Params.OrderBy = mv.columns[col]
-		mv.Params.Dec = false
-		err = mv.updateTableViewContents()
-	case 'O':
-		_, col := mv.TableView.GetSelected()
-		mv.Params.OrderBy = mv.columns[col]
-		mv.Params.Dec = true
-		err = mv.updateTableViewContents()
-	case 'i':
-		row, col := mv.TableView.GetSelected()
-		key := mv.entries[row][primary].Format("")
-		// TODO should use an Update so table can modify any necessary internals
-		new, err := mv.Table.Entries[key][col].Add(1)
-		if err != nil {
-			return
-		}
-		mv.Table.Entries[key][col] = new
-		err = mv.updateTableViewContents()
-	case 'I':
-		row, col := mv.TableView.GetSelected()
-		key := mv.entries[row][primary].Format("")
-		// TODO should use an Update so table can modify any necessary internals
-		new, err := mv.Table.Entries[key][col].Add(-1)
-		if err != nil {
-			return
-		}
-		mv.Table.Entries[key][col] = new
-		err = mv.updateTableViewContents()
-	case 's':
-		err = mv.saveContents()
-	case 'n':
-		mv.newEntry()
+	if binding, ok := active.Lookup(key, ch); ok {
+		err = binding.handler(mv)
 	}
 }
 
@@ -291,25 +504,62 @@ func (mv *MainView) updateTableViewContents() error {
 	}
 	mv.TableView.Header = header
 
+	mv.Params.Filter = mv.filter
 	entries, err := mv.Table.Query(mv.Params)
 	if err != nil {
 		return err
 	}
 	mv.entries = entries
-	for _, row := range mv.entries {
+	primary := mv.Table.Primary()
+	mv.TableView.Highlights = make([][][]int, len(entries))
+	mv.TableView.Selected = map[int]bool{}
+	for i, row := range entries {
 		// TODO ignore hidden columns
-		formatted := []string{}
-		for _, entry := range row {
+		formatted := make([]string, len(row))
+		rowHighlights := make([][]int, len(row))
+		for j, entry := range row {
 			// TODO extract actual formatting
-			formatted = append(formatted, entry.Format(""))
+			text := entry.Format("")
+			formatted[j] = text
+			if mv.filter != "" {
+				rowHighlights[j] = fuzzyScore(mv.filter, text).Positions
+			}
 		}
 		mv.TableView.Values = append(mv.TableView.Values, formatted)
+		mv.TableView.Highlights[i] = rowHighlights
+		if mv.selection.marked[row[primary].Format("")] {
+			mv.TableView.Selected[i] = true
+		}
 	}
+	mv.TableView.Clamp()
 	return nil
 }
 
 func (mv *MainView) promptExit(contents string, finish bool, err error) {
 	current := mv.Mode
+	if current == MainViewModeFilter {
+		if err == errPromptCanceled {
+			mv.filter = mv.preEditFilter
+			mv.updateTableViewContents()
+			mv.switchMode(MainViewModeTable)
+			return
+		}
+		mv.filter = contents
+		uerr := mv.updateTableViewContents()
+		if !finish {
+			return
+		}
+		if err == nil {
+			err = uerr
+		}
+		if err != nil {
+			mv.switchMode(MainViewModeAlert)
+			mv.alert = err.Error()
+			return
+		}
+		mv.switchMode(MainViewModeTable)
+		return
+	}
 	if !finish {
 		return
 	}
@@ -329,33 +579,39 @@ func (mv *MainView) promptExit(contents string, finish bool, err error) {
 		row, column := mv.TableView.GetSelected()
 		primary := mv.Table.Primary()
 		key := mv.entries[row][primary].Format("")
-		err = mv.Table.Update(key, mv.Table.Columns[column], contents)
-		if err != nil {
-			return
-		}
-		err = mv.updateTableViewContents()
+		before := mv.entries[row][column].Format("")
+		err = mv.doCommand(&updateCellCommand{
+			mv:     mv,
+			key:    key,
+			column: mv.Table.Columns[column],
+			before: before,
+			after:  contents,
+		})
 		return
 	case MainViewModePrompt:
-		parts := strings.Split(contents, " ")
-		if len(parts) == 0 {
-			return
-		}
-		command := parts[0]
-		switch command {
-		case "create-new-entry":
-			if len(parts) != 2 {
-				err = fmt.Errorf("create-new-entry takes 1 arg")
-				return
-			}
-			newPK := parts[1]
-			err = mv.Table.Insert(newPK)
-			if err != nil {
-				return
-			}
-			err = mv.updateTableViewContents()
+		if mv.bulkPrompt {
+			mv.bulkPrompt = false
+			err = mv.runBulk(contents)
 			return
-		default:
-			err = fmt.Errorf("unknown command: %s", contents)
 		}
+		err = mv.runCommandLine(contents)
+		return
+	}
+}
+
+// runCommandLine looks up and invokes the registered command named by
+// the first token of line, recording line in mv.history. Used for
+// both interactive command-mode input and the source command.
+func (mv *MainView) runCommandLine(line string) error {
+	parts := strings.Split(line, " ")
+	if len(parts) == 0 || parts[0] == "" {
+		return nil
+	}
+	mv.history = append(mv.history, line)
+	name := parts[0]
+	cmd, ok := mv.commands.Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown command: %s", line)
 	}
+	return cmd.Handler(mv, parts[1:])
 }
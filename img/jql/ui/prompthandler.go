@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+// errPromptCanceled is passed to Callback when Esc cancels the
+// prompt rather than committing it, so MainViewModeFilter can
+// distinguish "apply this buffer" from "abandon the edit" — both of
+// which reach Callback via the same Enter/Esc path.
+var errPromptCanceled = errors.New("prompt canceled")
+
+// PromptHandler is the gocui.Editor for the "prompt" view. Ordinary
+// keys fall through to gocui.DefaultEditor; Tab completes the
+// command-mode token against mv's registered commands, Up/Down walk
+// mv.history, and Enter/Esc commit the line via Callback.
+type PromptHandler struct {
+	// Callback is invoked with the prompt's current contents on every
+	// edit (finish=false, so MainViewModeFilter can apply live) and
+	// again on Enter/Esc (finish=true).
+	Callback func(contents string, finish bool, err error)
+
+	mv *MainView
+	// histPos is the index into mv.history currently shown while
+	// browsing with Up/Down, or -1 when not browsing.
+	histPos int
+	// completing is true while repeated Tab presses are cycling
+	// through completeMatches (at completeIdx); any other key resets
+	// it so the next Tab starts a fresh completion.
+	completing      bool
+	completeMatches []string
+	completeIdx     int
+}
+
+// NewPromptHandler returns a PromptHandler wired to mv's commands and
+// history, committing lines via mv.promptExit.
+func NewPromptHandler(mv *MainView) *PromptHandler {
+	return &PromptHandler{mv: mv, Callback: mv.promptExit, histPos: -1}
+}
+
+// Edit implements gocui.Editor.
+func (p *PromptHandler) Edit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	switch key {
+	case gocui.KeyEnter:
+		p.Callback(currentLine(v), true, nil)
+		return
+	case gocui.KeyEsc:
+		if p.mv != nil && p.mv.Mode == MainViewModeFilter {
+			p.Callback(currentLine(v), true, errPromptCanceled)
+			return
+		}
+		p.Callback(currentLine(v), true, nil)
+		return
+	case gocui.KeyTab:
+		p.complete(v)
+	case gocui.KeyArrowUp:
+		p.walkHistory(v, -1)
+	case gocui.KeyArrowDown:
+		p.walkHistory(v, 1)
+	default:
+		gocui.DefaultEditor.Edit(v, key, ch, mod)
+		p.histPos = -1
+		p.completing = false
+	}
+	p.Callback(currentLine(v), false, nil)
+}
+
+func currentLine(v *gocui.View) string {
+	return strings.TrimRight(v.Buffer(), "\n")
+}
+
+func replaceLine(v *gocui.View, s string) {
+	v.Clear()
+	v.Write([]byte(s))
+	v.MoveCursor(len([]rune(s)), 0, true)
+}
+
+// complete replaces the prompt's command token with the next
+// completion against mv's registered commands, per nextCompletion.
+func (p *PromptHandler) complete(v *gocui.View) {
+	if p.mv == nil || p.mv.Mode != MainViewModePrompt {
+		return
+	}
+	next, ok := p.nextCompletion(currentLine(v))
+	if !ok {
+		return
+	}
+	replaceLine(v, next+" ")
+}
+
+// nextCompletion returns the next completion for line against mv's
+// registered commands, threading p's cycle state, and whether a
+// completion is available. The first Tab on a prefix completes to
+// the first match; repeated Tabs (while p.completing stays true)
+// cycle through the rest of completeMatches, wrapping back to the
+// first.
+func (p *PromptHandler) nextCompletion(line string) (string, bool) {
+	if p.completing {
+		p.completeIdx = (p.completeIdx + 1) % len(p.completeMatches)
+		return p.completeMatches[p.completeIdx], true
+	}
+	if strings.Contains(line, " ") {
+		return "", false
+	}
+	matches := p.mv.commands.Complete(line)
+	if len(matches) == 0 {
+		return "", false
+	}
+	p.completeMatches = matches
+	p.completeIdx = 0
+	p.completing = true
+	return matches[0], true
+}
+
+// promptKeyMap describes the bindings PromptHandler.Edit dispatches
+// on in MainViewModePrompt, for the help overlay. Prompt's actual
+// dispatch stays the switch in Edit above rather than a KeyMap
+// lookup, since PromptHandler is a gocui.Editor wired to a different
+// view than MainView; handler is never invoked for these bindings.
+func promptKeyMap() KeyMap {
+	noop := func(mv *MainView) error { return nil }
+	return KeyMap{
+		Mode: MainViewModePrompt,
+		Bindings: []Binding{
+			{Key: gocui.KeyEnter, Help: "submit", Description: "Run the entered command", handler: noop},
+			{Key: gocui.KeyEsc, Help: "submit", Description: "Run the entered command, as Enter does", handler: noop},
+			{Key: gocui.KeyTab, Help: "complete", Description: "Complete the command token, cycling through matches", handler: noop},
+			{Key: gocui.KeyArrowUp, Help: "older", Description: "Recall the previous history entry", handler: noop},
+			{Key: gocui.KeyArrowDown, Help: "newer", Description: "Recall the next history entry", handler: noop},
+		},
+	}
+}
+
+// editKeyMap describes the bindings available in MainViewModeEdit,
+// for the help overlay. Edit reuses PromptHandler's view and Enter/Esc
+// dispatch (see promptExit), so it has no Tab/history bindings of its
+// own; handler is never invoked for these bindings.
+func editKeyMap() KeyMap {
+	noop := func(mv *MainView) error { return nil }
+	return KeyMap{
+		Mode: MainViewModeEdit,
+		Bindings: []Binding{
+			{Key: gocui.KeyEnter, Help: "submit", Description: "Commit the edited cell value", handler: noop},
+			{Key: gocui.KeyEsc, Help: "commit", Description: "Commit the prompt's current contents unchanged", handler: noop},
+		},
+	}
+}
+
+// walkHistory moves delta steps through mv.history (-1 is older, 1 is
+// newer) and replaces the prompt's contents with the entry landed on,
+// or clears it once past the most recent entry.
+func (p *PromptHandler) walkHistory(v *gocui.View, delta int) {
+	if p.mv == nil || p.mv.Mode != MainViewModePrompt || len(p.mv.history) == 0 {
+		return
+	}
+	if p.histPos == -1 {
+		p.histPos = len(p.mv.history)
+	}
+	p.histPos += delta
+	if p.histPos < 0 {
+		p.histPos = 0
+	}
+	if p.histPos >= len(p.mv.history) {
+		p.histPos = len(p.mv.history)
+		replaceLine(v, "")
+		return
+	}
+	replaceLine(v, p.mv.history[p.histPos])
+}
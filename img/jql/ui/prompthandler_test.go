@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jroimartin/gocui"
+)
+
+func TestPromptKeyMapDescribesEnterEscTabAndHistory(t *testing.T) {
+	km := promptKeyMap()
+	if km.Mode != MainViewModePrompt {
+		t.Fatalf("Mode = %v, want MainViewModePrompt", km.Mode)
+	}
+	for _, key := range []gocui.Key{gocui.KeyEnter, gocui.KeyEsc, gocui.KeyTab, gocui.KeyArrowUp, gocui.KeyArrowDown} {
+		if _, ok := km.Lookup(key, 0); !ok {
+			t.Fatalf("expected promptKeyMap to describe key %v", key)
+		}
+	}
+}
+
+func TestEditKeyMapDescribesEnterAndEsc(t *testing.T) {
+	km := editKeyMap()
+	if km.Mode != MainViewModeEdit {
+		t.Fatalf("Mode = %v, want MainViewModeEdit", km.Mode)
+	}
+	for _, key := range []gocui.Key{gocui.KeyEnter, gocui.KeyEsc} {
+		if _, ok := km.Lookup(key, 0); !ok {
+			t.Fatalf("expected editKeyMap to describe key %v", key)
+		}
+	}
+}
+
+func newTestPromptHandler(t *testing.T) *PromptHandler {
+	path := filepath.Join(t.TempDir(), "db.json")
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice"}}}`)
+	mv, err := NewMainView(path, "people")
+	if err != nil {
+		t.Fatalf("NewMainView: %v", err)
+	}
+	return NewPromptHandler(mv)
+}
+
+func TestNextCompletionCompletesUnambiguousPrefix(t *testing.T) {
+	p := newTestPromptHandler(t)
+	next, ok := p.nextCompletion("goto")
+	if !ok {
+		t.Fatalf("expected a completion for an unambiguous prefix")
+	}
+	if next != "goto" {
+		t.Fatalf("next = %q, want %q", next, "goto")
+	}
+}
+
+func TestNextCompletionCyclesThroughMultipleMatches(t *testing.T) {
+	p := newTestPromptHandler(t)
+	// "s" prefixes "set", "sort", and "source", sorted in that order.
+	want := []string{"set", "sort", "source", "set"}
+	for i, w := range want {
+		next, ok := p.nextCompletion("s")
+		if !ok {
+			t.Fatalf("tab %d: expected a completion", i)
+		}
+		if next != w {
+			t.Fatalf("tab %d: next = %q, want %q", i, next, w)
+		}
+	}
+}
+
+func TestNextCompletionStartsOverOnNewPrefix(t *testing.T) {
+	p := newTestPromptHandler(t)
+	if _, ok := p.nextCompletion("s"); !ok {
+		t.Fatalf("expected a completion for \"s\"")
+	}
+	p.completing = false // a non-Tab keystroke resets cycling, as Edit does
+	next, ok := p.nextCompletion("goto")
+	if !ok {
+		t.Fatalf("expected a completion for the new prefix")
+	}
+	if next != "goto" {
+		t.Fatalf("next = %q, want %q", next, "goto")
+	}
+}
+
+func TestNextCompletionNoMatchesIsNotOK(t *testing.T) {
+	p := newTestPromptHandler(t)
+	if _, ok := p.nextCompletion("zzz"); ok {
+		t.Fatalf("expected no completion for an unmatched prefix")
+	}
+}
@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+	"github.com/ulmenhaus/env/img/jql/types"
+)
+
+// bulkCommandNames lists the bulk actions available from the 'b'
+// bulk menu, each applied to every marked row as a single atomic
+// undo entry.
+var bulkCommandNames = []string{"set", "bump", "delete"}
+
+// selectKeyMap is the set of bindings available in
+// MainViewModeSelect: movement plus marking and the bulk-command
+// prompt. It is built fresh per lookup (cheap, small) rather than
+// stored on MainView, mirroring how defaultTableKeyMap is a pure
+// function of no state.
+func selectKeyMap() KeyMap {
+	table := defaultTableKeyMap()
+	bindings := []Binding{
+		{Keys: []rune{' '}, Key: gocui.KeySpace, Help: "mark", Description: "Mark or unmark the current row",
+			handler: func(mv *MainView) error { return mv.toggleMarkSelected() }},
+		{Keys: []rune{'V'}, Help: "mark range", Description: "Mark every row between the anchor and the cursor",
+			handler: func(mv *MainView) error { return mv.markRange() }},
+		{Keys: []rune{'b'}, Help: "bulk menu", Description: "List bulk commands applicable to the marked rows",
+			handler: func(mv *MainView) error {
+				mv.promptText = strings.Join(bulkCommandNames, "|") + " "
+				mv.bulkPrompt = true
+				mv.switchMode(MainViewModePrompt)
+				return nil
+			}},
+		{Key: gocui.KeyEsc, Help: "exit select", Description: "Leave select mode without applying any action",
+			handler: func(mv *MainView) error {
+				mv.switchMode(MainViewModeTable)
+				return nil
+			}},
+	}
+	for _, b := range table.Bindings {
+		switch b.Key {
+		case gocui.KeyArrowRight, gocui.KeyArrowLeft, gocui.KeyArrowUp, gocui.KeyArrowDown:
+			bindings = append(bindings, b)
+		}
+	}
+	return KeyMap{Mode: MainViewModeSelect, Bindings: bindings}
+}
+
+// selectState tracks which rows are marked in MainViewModeSelect,
+// keyed by primary key.
+type selectState struct {
+	marked map[string]bool
+	anchor int // row index Space/V range-selection started from, -1 if none
+}
+
+func newSelectState() selectState {
+	return selectState{marked: map[string]bool{}, anchor: -1}
+}
+
+// enterSelectMode switches to MainViewModeSelect, starting a fresh
+// selection anchored at the current row.
+func (mv *MainView) enterSelectMode() {
+	mv.selection = newSelectState()
+	row, _ := mv.TableView.GetSelected()
+	mv.selection.anchor = row
+	mv.switchMode(MainViewModeSelect)
+}
+
+// toggleMarkSelected marks or unmarks the current row.
+func (mv *MainView) toggleMarkSelected() error {
+	row, _ := mv.TableView.GetSelected()
+	primary := mv.Table.Primary()
+	key := mv.entries[row][primary].Format("")
+	if mv.selection.marked[key] {
+		delete(mv.selection.marked, key)
+	} else {
+		mv.selection.marked[key] = true
+	}
+	return mv.updateTableViewContents()
+}
+
+// markRange marks every row between the selection anchor and the
+// current row, inclusive, as used by the 'V' range-select binding.
+func (mv *MainView) markRange() error {
+	row, _ := mv.TableView.GetSelected()
+	primary := mv.Table.Primary()
+	start, end := mv.selection.anchor, row
+	if start > end {
+		start, end = end, start
+	}
+	for i := start; i <= end; i++ {
+		key := mv.entries[i][primary].Format("")
+		mv.selection.marked[key] = true
+	}
+	return mv.updateTableViewContents()
+}
+
+// selectedKeys returns the primary keys currently marked.
+func (mv *MainView) selectedKeys() []string {
+	keys := make([]string, 0, len(mv.selection.marked))
+	for k := range mv.selection.marked {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// bulkCommand is an UndoableCommand that applies a per-row action to
+// every marked entry as a single atomic entry on the undo stack.
+type bulkCommand struct {
+	keys    []string
+	forward func(key string) error
+	reverse func(key string) error
+}
+
+func (c *bulkCommand) Do() error   { return c.apply(c.forward) }
+func (c *bulkCommand) Undo() error { return c.apply(c.reverse) }
+func (c *bulkCommand) Redo() error { return c.Do() }
+
+func (c *bulkCommand) apply(f func(key string) error) error {
+	for _, key := range c.keys {
+		if err := f(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bulkSetColumn returns a bulkCommand that sets column to value for
+// every marked row, recording each row's prior value for undo.
+func (mv *MainView) bulkSetColumn(column, value string) (UndoableCommand, error) {
+	keys := mv.selectedKeys()
+	before := map[string]string{}
+	colIdx := -1
+	for i, c := range mv.Table.Columns {
+		if c == column {
+			colIdx = i
+		}
+	}
+	if colIdx == -1 {
+		return nil, fmt.Errorf("unknown column: %s", column)
+	}
+	for _, key := range keys {
+		before[key] = mv.Table.Entries[key][colIdx].Format("")
+	}
+	return &bulkCommand{
+		keys: keys,
+		forward: func(key string) error {
+			mv.markDirty(key)
+			return mv.Table.Update(key, column, value)
+		},
+		reverse: func(key string) error {
+			mv.markDirty(key)
+			return mv.Table.Update(key, column, before[key])
+		},
+	}, nil
+}
+
+// bulkBumpColumn returns a bulkCommand that adds delta to column for
+// every marked row.
+func (mv *MainView) bulkBumpColumn(column string, delta int) (UndoableCommand, error) {
+	colIdx := -1
+	for i, c := range mv.Table.Columns {
+		if c == column {
+			colIdx = i
+		}
+	}
+	if colIdx == -1 {
+		return nil, fmt.Errorf("unknown column: %s", column)
+	}
+	keys := mv.selectedKeys()
+	before := map[string]string{}
+	after := map[string]string{}
+	for _, key := range keys {
+		entry := mv.Table.Entries[key][colIdx]
+		before[key] = entry.Format("")
+		bumped, err := entry.Add(delta)
+		if err != nil {
+			return nil, err
+		}
+		after[key] = bumped.Format("")
+	}
+	return &bulkCommand{
+		keys: keys,
+		forward: func(key string) error {
+			mv.markDirty(key)
+			return mv.Table.Update(key, column, after[key])
+		},
+		reverse: func(key string) error {
+			mv.markDirty(key)
+			return mv.Table.Update(key, column, before[key])
+		},
+	}, nil
+}
+
+// bulkDeleteRows returns a bulkCommand that deletes every marked row,
+// recording each row's entries up front so Undo can restore them,
+// mirroring deleteEntryCommand but as one atomic entry for the whole
+// selection rather than one push per row.
+func (mv *MainView) bulkDeleteRows() (UndoableCommand, error) {
+	keys := mv.selectedKeys()
+	before := map[string][]types.Entry{}
+	for _, key := range keys {
+		before[key] = append([]types.Entry{}, mv.Table.Entries[key]...)
+	}
+	return &bulkCommand{
+		keys: keys,
+		forward: func(key string) error {
+			mv.markDirty(key)
+			return mv.Table.Delete(key)
+		},
+		reverse: func(key string) error {
+			mv.markDirty(key)
+			if err := mv.Table.Insert(key); err != nil {
+				return err
+			}
+			for i, col := range mv.Table.Columns {
+				if err := mv.Table.Update(key, col, before[key][i].Format("")); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, nil
+}
+
+// runBulk parses a line submitted from the 'b' bulk menu and applies
+// it to the whole selection as a single atomic undo entry: set and
+// bump via bulkSetColumn/bulkBumpColumn, delete via bulkDeleteRows.
+// All three go through doCommand so exactly one entry lands on the
+// undo stack per bulk action, regardless of how many rows it touched.
+func (mv *MainView) runBulk(line string) error {
+	parts := strings.Fields(line)
+	if len(parts) == 0 {
+		return nil
+	}
+	switch parts[0] {
+	case "set", "bump":
+		return mv.runCommandLine(line)
+	case "delete":
+		cmd, err := mv.bulkDeleteRows()
+		if err != nil {
+			return err
+		}
+		return mv.doCommand(cmd)
+	default:
+		return fmt.Errorf("unknown bulk command: %s", parts[0])
+	}
+}
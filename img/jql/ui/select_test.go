@@ -0,0 +1,201 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newSelectTestMainView(t *testing.T) *MainView {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "db.json")
+	writeTestJSON(t, path, `{"people": {
+		"1": {"id": "1", "name": "alice", "age": "30"},
+		"2": {"id": "2", "name": "bob", "age": "40"},
+		"3": {"id": "3", "name": "carol", "age": "50"}
+	}}`)
+
+	mv, err := NewMainView(path, "people")
+	if err != nil {
+		t.Fatalf("NewMainView: %v", err)
+	}
+	mv.enterSelectMode()
+	return mv
+}
+
+// colIndex returns mv.Table's index for column, failing the test if
+// it isn't found, so assertions don't depend on tableFromRaw's
+// alphabetical column ordering.
+func colIndex(t *testing.T, mv *MainView, column string) int {
+	t.Helper()
+	for i, c := range mv.Table.Columns {
+		if c == column {
+			return i
+		}
+	}
+	t.Fatalf("column %q not found in %v", column, mv.Table.Columns)
+	return -1
+}
+
+func TestToggleMarkSelectedMarksAndUnmarks(t *testing.T) {
+	mv := newSelectTestMainView(t)
+
+	if err := mv.toggleMarkSelected(); err != nil {
+		t.Fatalf("toggleMarkSelected: %v", err)
+	}
+	if !mv.selection.marked["1"] {
+		t.Fatalf("expected row 1 to be marked")
+	}
+	if err := mv.toggleMarkSelected(); err != nil {
+		t.Fatalf("toggleMarkSelected: %v", err)
+	}
+	if mv.selection.marked["1"] {
+		t.Fatalf("expected a second toggle to unmark row 1")
+	}
+}
+
+func TestMarkRangeMarksBetweenAnchorAndCursorInclusive(t *testing.T) {
+	mv := newSelectTestMainView(t)
+	mv.selection.anchor = 0
+	mv.TableView.row = 2
+
+	if err := mv.markRange(); err != nil {
+		t.Fatalf("markRange: %v", err)
+	}
+	for _, key := range []string{"1", "2", "3"} {
+		if !mv.selection.marked[key] {
+			t.Fatalf("expected row %s to be marked, got selection %v", key, mv.selection.marked)
+		}
+	}
+}
+
+func TestMarkRangeHandlesCursorBeforeAnchor(t *testing.T) {
+	mv := newSelectTestMainView(t)
+	mv.selection.anchor = 2
+	mv.TableView.row = 0
+
+	if err := mv.markRange(); err != nil {
+		t.Fatalf("markRange: %v", err)
+	}
+	for _, key := range []string{"1", "2", "3"} {
+		if !mv.selection.marked[key] {
+			t.Fatalf("expected row %s to be marked when the cursor starts above the anchor", key)
+		}
+	}
+}
+
+func TestBulkSetColumnSetsAndUndoesEveryMarkedRow(t *testing.T) {
+	mv := newSelectTestMainView(t)
+	mv.selection.marked["1"] = true
+	mv.selection.marked["3"] = true
+
+	nameIdx := colIndex(t, mv, "name")
+	cmd, err := mv.bulkSetColumn("name", "same-name")
+	if err != nil {
+		t.Fatalf("bulkSetColumn: %v", err)
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if got := mv.Table.Entries["1"][nameIdx].Format(""); got != "same-name" {
+		t.Fatalf("row 1 name = %q, want %q", got, "same-name")
+	}
+	if got := mv.Table.Entries["3"][nameIdx].Format(""); got != "same-name" {
+		t.Fatalf("row 3 name = %q, want %q", got, "same-name")
+	}
+	if got := mv.Table.Entries["2"][nameIdx].Format(""); got != "bob" {
+		t.Fatalf("expected unmarked row 2 to be untouched, got %q", got)
+	}
+	if !mv.dirty["people"]["1"] || !mv.dirty["people"]["3"] {
+		t.Fatalf("expected Do to mark every affected row dirty")
+	}
+
+	if err := cmd.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if got := mv.Table.Entries["1"][nameIdx].Format(""); got != "alice" {
+		t.Fatalf("expected Undo to restore row 1, got %q", got)
+	}
+	if got := mv.Table.Entries["3"][nameIdx].Format(""); got != "carol" {
+		t.Fatalf("expected Undo to restore row 3, got %q", got)
+	}
+}
+
+func TestBulkSetColumnUnknownColumnErrors(t *testing.T) {
+	mv := newSelectTestMainView(t)
+	if _, err := mv.bulkSetColumn("nope", "x"); err == nil {
+		t.Fatalf("expected an error for an unknown column")
+	}
+}
+
+func TestBulkBumpColumnAddsDeltaAndUndoes(t *testing.T) {
+	mv := newSelectTestMainView(t)
+	mv.selection.marked["1"] = true
+	mv.selection.marked["2"] = true
+
+	ageIdx := colIndex(t, mv, "age")
+	cmd, err := mv.bulkBumpColumn("age", 5)
+	if err != nil {
+		t.Fatalf("bulkBumpColumn: %v", err)
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if got := mv.Table.Entries["1"][ageIdx].Format(""); got != "35" {
+		t.Fatalf("row 1 age = %q, want %q", got, "35")
+	}
+	if got := mv.Table.Entries["2"][ageIdx].Format(""); got != "45" {
+		t.Fatalf("row 2 age = %q, want %q", got, "45")
+	}
+
+	if err := cmd.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if got := mv.Table.Entries["1"][ageIdx].Format(""); got != "30" {
+		t.Fatalf("expected Undo to restore row 1's age, got %q", got)
+	}
+	if got := mv.Table.Entries["2"][ageIdx].Format(""); got != "40" {
+		t.Fatalf("expected Undo to restore row 2's age, got %q", got)
+	}
+}
+
+func TestBulkBumpColumnNonNumericEntryErrors(t *testing.T) {
+	mv := newSelectTestMainView(t)
+	mv.selection.marked["1"] = true
+
+	if _, err := mv.bulkBumpColumn("name", 1); err == nil {
+		t.Fatalf("expected an error bumping a non-numeric column")
+	}
+}
+
+func TestBulkDeleteRowsDeletesAndUndoesEveryMarkedRow(t *testing.T) {
+	mv := newSelectTestMainView(t)
+	mv.selection.marked["2"] = true
+
+	cmd, err := mv.bulkDeleteRows()
+	if err != nil {
+		t.Fatalf("bulkDeleteRows: %v", err)
+	}
+	if err := cmd.Do(); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if _, ok := mv.Table.Entries["2"]; ok {
+		t.Fatalf("expected row 2 to be deleted")
+	}
+	if !mv.dirty["people"]["2"] {
+		t.Fatalf("expected Do to mark the deleted row dirty")
+	}
+
+	if err := cmd.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	row, ok := mv.Table.Entries["2"]
+	if !ok {
+		t.Fatalf("expected Undo to restore row 2")
+	}
+	if got := row[colIndex(t, mv, "name")].Format(""); got != "bob" {
+		t.Fatalf("restored row 2 name = %q, want %q", got, "bob")
+	}
+	if got := row[colIndex(t, mv, "age")].Format(""); got != "40" {
+		t.Fatalf("restored row 2 age = %q, want %q", got, "40")
+	}
+}
@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Direction is a cursor movement within a TableView.
+type Direction int
+
+const (
+	// DirectionUp moves the selected cell up a row.
+	DirectionUp Direction = iota
+	// DirectionDown moves the selected cell down a row.
+	DirectionDown
+	// DirectionLeft moves the selected cell left a column.
+	DirectionLeft
+	// DirectionRight moves the selected cell right a column.
+	DirectionRight
+)
+
+// TableView renders a table's header and rows into a gocui view and
+// tracks which cell is selected.
+type TableView struct {
+	Header []string
+	Values [][]string
+	Widths []int
+
+	// Highlights holds, per row and column, the rune positions of
+	// Values[row][col] that matched the active filter (as returned by
+	// fuzzyScore's Positions), so WriteContents can draw them in
+	// reverse video. A nil or short entry means no highlight.
+	Highlights [][][]int
+
+	// Selected marks which rows, by index into Values, are marked in
+	// MainViewModeSelect, so WriteContents can render a gutter.
+	Selected map[int]bool
+
+	row, col int
+}
+
+// Move shifts the selected cell one step in d, clamping at the edges
+// of the current Values/Widths.
+func (tv *TableView) Move(d Direction) {
+	switch d {
+	case DirectionUp:
+		if tv.row > 0 {
+			tv.row--
+		}
+	case DirectionDown:
+		if tv.row < len(tv.Values)-1 {
+			tv.row++
+		}
+	case DirectionLeft:
+		if tv.col > 0 {
+			tv.col--
+		}
+	case DirectionRight:
+		if tv.col < len(tv.Widths)-1 {
+			tv.col++
+		}
+	}
+	if tv.row < 0 {
+		tv.row = 0
+	}
+	if tv.col < 0 {
+		tv.col = 0
+	}
+}
+
+// GetSelected returns the currently selected row and column.
+func (tv *TableView) GetSelected() (int, int) {
+	return tv.row, tv.col
+}
+
+// Clamp pulls the selected row/col back within the current
+// Values/Widths bounds, as used after a content refresh (e.g. a
+// filter) shrinks the row count out from under the cursor.
+func (tv *TableView) Clamp() {
+	if tv.row > len(tv.Values)-1 {
+		tv.row = len(tv.Values) - 1
+	}
+	if tv.row < 0 {
+		tv.row = 0
+	}
+	if tv.col > len(tv.Widths)-1 {
+		tv.col = len(tv.Widths) - 1
+	}
+	if tv.col < 0 {
+		tv.col = 0
+	}
+}
+
+// reverseOn and reverseOff wrap highlighted substrings in reverse
+// video; gocui interprets these escape sequences itself rather than
+// passing them through to the terminal.
+const (
+	reverseOn  = "\x1b[7m"
+	reverseOff = "\x1b[0m"
+)
+
+// WriteContents renders the header and rows to w, padding cells to
+// Widths, marking a '*' gutter for Selected rows, and wrapping any
+// positions recorded in Highlights in reverse video.
+func (tv *TableView) WriteContents(w io.Writer) error {
+	if err := tv.writeRow(w, " ", tv.Header, nil); err != nil {
+		return err
+	}
+	for i, row := range tv.Values {
+		gutter := " "
+		if tv.Selected[i] {
+			gutter = "*"
+		}
+		var highlights [][]int
+		if i < len(tv.Highlights) {
+			highlights = tv.Highlights[i]
+		}
+		if err := tv.writeRow(w, gutter, row, highlights); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tv *TableView) writeRow(w io.Writer, gutter string, cells []string, highlights [][]int) error {
+	if _, err := fmt.Fprint(w, gutter); err != nil {
+		return err
+	}
+	for i, cell := range cells {
+		width := 20
+		if i < len(tv.Widths) {
+			width = tv.Widths[i]
+		}
+		var positions []int
+		if i < len(highlights) {
+			positions = highlights[i]
+		}
+		cell, positions = truncateRunes(cell, positions, width)
+		if _, err := fmt.Fprint(w, " "+pad(highlight(cell, positions), width)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, "\n")
+	return err
+}
+
+// highlight wraps the runes of cell at positions in reverse video.
+func highlight(cell string, positions []int) string {
+	if len(positions) == 0 {
+		return cell
+	}
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+	var b strings.Builder
+	open := false
+	for i, r := range []rune(cell) {
+		if marked[i] && !open {
+			b.WriteString(reverseOn)
+			open = true
+		} else if !marked[i] && open {
+			b.WriteString(reverseOff)
+			open = false
+		}
+		b.WriteRune(r)
+	}
+	if open {
+		b.WriteString(reverseOff)
+	}
+	return b.String()
+}
+
+// truncateRunes trims cell to at most width runes, dropping any
+// highlight positions that fall outside the truncated range. It runs
+// before highlight() wraps the matched positions in reverse video, so
+// truncating never has to cut through (and drop) an escape sequence.
+func truncateRunes(cell string, positions []int, width int) (string, []int) {
+	runes := []rune(cell)
+	if len(runes) <= width {
+		return cell, positions
+	}
+	kept := make([]int, 0, len(positions))
+	for _, p := range positions {
+		if p < width {
+			kept = append(kept, p)
+		}
+	}
+	return string(runes[:width]), kept
+}
+
+// pad right-pads cell to width visible runes, ignoring the escape
+// sequences highlight may have added.
+func pad(cell string, width int) string {
+	visible := strings.ReplaceAll(strings.ReplaceAll(cell, reverseOn, ""), reverseOff, "")
+	return cell + strings.Repeat(" ", width-len([]rune(visible)))
+}
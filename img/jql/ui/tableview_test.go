@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTableViewMoveClampsAtEdges(t *testing.T) {
+	tv := &TableView{
+		Values: [][]string{{"a", "b"}, {"c", "d"}},
+		Widths: []int{5, 5},
+	}
+	tv.Move(DirectionUp)
+	if row, _ := tv.GetSelected(); row != 0 {
+		t.Fatalf("Move(Up) from row 0 = %d, want 0", row)
+	}
+	tv.Move(DirectionDown)
+	tv.Move(DirectionDown)
+	if row, _ := tv.GetSelected(); row != 1 {
+		t.Fatalf("Move(Down) past the last row = %d, want 1", row)
+	}
+	tv.Move(DirectionLeft)
+	if _, col := tv.GetSelected(); col != 0 {
+		t.Fatalf("Move(Left) from col 0 = %d, want 0", col)
+	}
+}
+
+func TestTableViewWriteContentsHighlightsMatches(t *testing.T) {
+	tv := &TableView{
+		Header:     []string{"name"},
+		Values:     [][]string{{"alice"}},
+		Widths:     []int{10},
+		Highlights: [][][]int{{{0, 1, 2}}},
+		Selected:   map[int]bool{},
+	}
+	var buf strings.Builder
+	if err := tv.WriteContents(&buf); err != nil {
+		t.Fatalf("WriteContents: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, reverseOn) || !strings.Contains(out, reverseOff) {
+		t.Fatalf("expected highlighted output to contain reverse-video escapes, got %q", out)
+	}
+}
+
+func TestTableViewWriteContentsHighlightsMatchesPastColumnWidth(t *testing.T) {
+	tv := &TableView{
+		Header:     []string{"name"},
+		Values:     [][]string{{"alicealicealicealice"}},
+		Widths:     []int{5},
+		Highlights: [][][]int{{{0, 1, 2}}},
+		Selected:   map[int]bool{},
+	}
+	var buf strings.Builder
+	if err := tv.WriteContents(&buf); err != nil {
+		t.Fatalf("WriteContents: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, reverseOn) || !strings.Contains(out, reverseOff) {
+		t.Fatalf("expected a match within the truncated width to still be highlighted, got %q", out)
+	}
+}
+
+func TestTableViewWriteContentsMarksSelectedGutter(t *testing.T) {
+	tv := &TableView{
+		Header:   []string{"name"},
+		Values:   [][]string{{"alice"}, {"bob"}},
+		Widths:   []int{10},
+		Selected: map[int]bool{1: true},
+	}
+	var buf strings.Builder
+	if err := tv.WriteContents(&buf); err != nil {
+		t.Fatalf("WriteContents: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header row plus 2 data rows, got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[1], " ") {
+		t.Fatalf("unmarked row should have a blank gutter, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "*") {
+		t.Fatalf("marked row should have a '*' gutter, got %q", lines[2])
+	}
+}
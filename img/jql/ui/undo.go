@@ -0,0 +1,152 @@
+package ui
+
+import "github.com/ulmenhaus/env/img/jql/types"
+
+// UndoableCommand is a single undoable mutation of the current
+// table. Do is invoked once when the mutation is first applied; Undo
+// and Redo are invoked on 'u' / Ctrl-R respectively.
+type UndoableCommand interface {
+	Do() error
+	Undo() error
+	Redo() error
+}
+
+// maxUndoDepth bounds the undo stack so long sessions don't grow it
+// unbounded.
+const maxUndoDepth = 100
+
+// undoStack is a bounded stack of applied UndoableCommands, with a
+// separate redo stack that is invalidated whenever a new
+// UndoableCommand is pushed.
+type undoStack struct {
+	undone []UndoableCommand
+	redone []UndoableCommand
+}
+
+// push applies cmd via Do, then records it for undo, clearing any
+// pending redo.
+func (s *undoStack) push(cmd UndoableCommand) error {
+	if err := cmd.Do(); err != nil {
+		return err
+	}
+	s.undone = append(s.undone, cmd)
+	if len(s.undone) > maxUndoDepth {
+		s.undone = s.undone[len(s.undone)-maxUndoDepth:]
+	}
+	s.redone = nil
+	return nil
+}
+
+// undo reverts the most recently applied command, if any.
+func (s *undoStack) undo() error {
+	if len(s.undone) == 0 {
+		return nil
+	}
+	cmd := s.undone[len(s.undone)-1]
+	if err := cmd.Undo(); err != nil {
+		return err
+	}
+	s.undone = s.undone[:len(s.undone)-1]
+	s.redone = append(s.redone, cmd)
+	return nil
+}
+
+// redo reapplies the most recently undone command, if any.
+func (s *undoStack) redo() error {
+	if len(s.redone) == 0 {
+		return nil
+	}
+	cmd := s.redone[len(s.redone)-1]
+	if err := cmd.Redo(); err != nil {
+		return err
+	}
+	s.redone = s.redone[:len(s.redone)-1]
+	s.undone = append(s.undone, cmd)
+	return nil
+}
+
+// updateCellCommand is an UndoableCommand that sets a single cell to
+// a new value, as used by cell edits and increment/decrement.
+type updateCellCommand struct {
+	mv     *MainView
+	key    string
+	column string
+	before string
+	after  string
+}
+
+func (c *updateCellCommand) Do() error {
+	c.mv.markDirty(c.key)
+	return c.mv.Table.Update(c.key, c.column, c.after)
+}
+
+func (c *updateCellCommand) Undo() error {
+	c.mv.markDirty(c.key)
+	return c.mv.Table.Update(c.key, c.column, c.before)
+}
+
+func (c *updateCellCommand) Redo() error {
+	return c.Do()
+}
+
+// insertEntryCommand is an UndoableCommand that inserts a new entry
+// by primary key, as used by create-new-entry.
+type insertEntryCommand struct {
+	mv *MainView
+	pk string
+}
+
+func (c *insertEntryCommand) Do() error {
+	c.mv.markDirty(c.pk)
+	return c.mv.Table.Insert(c.pk)
+}
+
+func (c *insertEntryCommand) Undo() error {
+	c.mv.markDirty(c.pk)
+	return c.mv.Table.Delete(c.pk)
+}
+
+func (c *insertEntryCommand) Redo() error {
+	return c.Do()
+}
+
+// deleteEntryCommand is an UndoableCommand that removes an entry by
+// primary key, as used by the delete command. It records the
+// entry's values on Do so Undo can restore them.
+type deleteEntryCommand struct {
+	mv      *MainView
+	pk      string
+	entries []types.Entry
+}
+
+func (c *deleteEntryCommand) Do() error {
+	c.mv.markDirty(c.pk)
+	c.entries = append([]types.Entry{}, c.mv.Table.Entries[c.pk]...)
+	return c.mv.Table.Delete(c.pk)
+}
+
+func (c *deleteEntryCommand) Undo() error {
+	c.mv.markDirty(c.pk)
+	if err := c.mv.Table.Insert(c.pk); err != nil {
+		return err
+	}
+	for i, col := range c.mv.Table.Columns {
+		if err := c.mv.Table.Update(c.pk, col, c.entries[i].Format("")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *deleteEntryCommand) Redo() error {
+	return c.Do()
+}
+
+// doCommand pushes cmd onto mv's undo stack, applying it, and
+// refreshes the table view contents on success.
+func (mv *MainView) doCommand(cmd UndoableCommand) error {
+	if err := mv.undoStack.push(cmd); err != nil {
+		return err
+	}
+	return mv.updateTableViewContents()
+}
@@ -0,0 +1,69 @@
+package ui
+
+import "testing"
+
+// recordingCommand is a fake UndoableCommand used to verify
+// undoStack's ordering without depending on MainView.
+type recordingCommand struct {
+	log   *[]string
+	label string
+}
+
+func (c *recordingCommand) Do() error   { *c.log = append(*c.log, "do:"+c.label); return nil }
+func (c *recordingCommand) Undo() error { *c.log = append(*c.log, "undo:"+c.label); return nil }
+func (c *recordingCommand) Redo() error { *c.log = append(*c.log, "redo:"+c.label); return nil }
+
+func TestUndoStackPushUndoRedo(t *testing.T) {
+	var log []string
+	var s undoStack
+
+	if err := s.push(&recordingCommand{log: &log, label: "a"}); err != nil {
+		t.Fatalf("push a: %v", err)
+	}
+	if err := s.push(&recordingCommand{log: &log, label: "b"}); err != nil {
+		t.Fatalf("push b: %v", err)
+	}
+	if err := s.undo(); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if err := s.redo(); err != nil {
+		t.Fatalf("redo: %v", err)
+	}
+
+	want := []string{"do:a", "do:b", "undo:b", "redo:b"}
+	if len(log) != len(want) {
+		t.Fatalf("log = %v, want %v", log, want)
+	}
+	for i := range want {
+		if log[i] != want[i] {
+			t.Fatalf("log = %v, want %v", log, want)
+		}
+	}
+}
+
+func TestUndoStackPushClearsRedo(t *testing.T) {
+	var log []string
+	var s undoStack
+
+	s.push(&recordingCommand{log: &log, label: "a"})
+	s.undo()
+	s.push(&recordingCommand{log: &log, label: "b"})
+	if err := s.redo(); err != nil {
+		t.Fatalf("redo: %v", err)
+	}
+	for _, entry := range log {
+		if entry == "redo:a" {
+			t.Fatalf("expected pushing a new command to clear the redo stack, got log %v", log)
+		}
+	}
+}
+
+func TestUndoStackUndoOnEmptyIsNoop(t *testing.T) {
+	var s undoStack
+	if err := s.undo(); err != nil {
+		t.Fatalf("undo on empty stack should be a no-op, got error: %v", err)
+	}
+	if err := s.redo(); err != nil {
+		t.Fatalf("redo on empty stack should be a no-op, got error: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/jroimartin/gocui"
+	"github.com/ulmenhaus/env/img/jql/osm"
+)
+
+// WatchForChanges starts a background goroutine that watches the file
+// at mv.path (with any jql:// prefix removed) for external modifications (e.g. another editor saving the same
+// file) and reloads the database when one is seen. It should be
+// called once the gocui.Gui is running, since reloads are applied
+// via g.Update to stay on the UI goroutine. The watcher is stopped
+// when done is closed.
+func (mv *MainView) WatchForChanges(g *gocui.Gui, done <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(strings.TrimPrefix(mv.path, "jql://")); err != nil {
+		watcher.Close()
+		return err
+	}
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-done:
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				g.Update(func(g *gocui.Gui) error {
+					return mv.reload()
+				})
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// reload re-reads mv.path from disk and reconciles it against any
+// unsaved edits (tracked in mv.dirty). Rows with no unsaved edit are
+// replaced outright, including rows removed on disk, which are
+// deleted from the in-memory table so a later save doesn't resurrect
+// them. Every row with an unsaved edit that is in the reloaded file,
+// or missing from it, is left as a conflict for the user to resolve,
+// surfaced via an alert; reload does not check whether the file
+// actually changed that row. When AutoMergeNonConflicting is set, the
+// file's version (or deletion) wins for those rows instead and their
+// dirty flags are cleared.
+func (mv *MainView) reload() error {
+	store, r, err := storeAndReader(mv.path)
+	if err != nil {
+		return err
+	}
+	if f, ok := r.(interface{ Close() error }); ok {
+		defer f.Close()
+	}
+	mapper, err := osm.NewObjectStoreMapper(store)
+	if err != nil {
+		return err
+	}
+	fresh, err := mapper.Load(r)
+	if err != nil {
+		return err
+	}
+
+	conflicts := []string{}
+	for name, table := range fresh.Tables {
+		current, ok := mv.DB.Tables[name]
+		if !ok {
+			mv.DB.Tables[name] = table
+			continue
+		}
+		for key, entry := range table.Entries {
+			if mv.dirty[name][key] {
+				if !mv.AutoMergeNonConflicting {
+					conflicts = append(conflicts, name+"/"+key)
+					continue
+				}
+				delete(mv.dirty[name], key)
+			}
+			current.Entries[key] = entry
+		}
+		for key := range current.Entries {
+			if _, ok := table.Entries[key]; ok {
+				continue
+			}
+			if mv.dirty[name][key] {
+				if !mv.AutoMergeNonConflicting {
+					conflicts = append(conflicts, name+"/"+key)
+					continue
+				}
+				delete(mv.dirty[name], key)
+			}
+			delete(current.Entries, key)
+		}
+	}
+
+	if err := mv.updateTableViewContents(); err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		mv.alert = "reload: unsaved edits conflict with changes on disk: " + joinKeys(conflicts)
+		mv.switchMode(MainViewModeAlert)
+	}
+	return nil
+}
+
+func joinKeys(keys []string) string {
+	out := ""
+	for i, k := range keys {
+		if i > 0 {
+			out += ", "
+		}
+		out += k
+	}
+	return out
+}
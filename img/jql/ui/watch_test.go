@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestJSON(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func TestReloadMergesNonConflictingRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice"}, "2": {"id": "2", "name": "bob"}}}`)
+
+	mv, err := NewMainView(path, "people")
+	if err != nil {
+		t.Fatalf("NewMainView: %v", err)
+	}
+
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice-on-disk"}, "2": {"id": "2", "name": "bob"}}}`)
+	if err := mv.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := mv.DB.Tables["people"].Entries["1"][1].Format(""); got != "alice-on-disk" {
+		t.Fatalf("expected row 1 to pick up the external edit, got %q", got)
+	}
+	if mv.alert != "" {
+		t.Fatalf("expected no conflict alert, got %q", mv.alert)
+	}
+}
+
+func TestReloadAlertsOnConflict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice"}}}`)
+
+	mv, err := NewMainView(path, "people")
+	if err != nil {
+		t.Fatalf("NewMainView: %v", err)
+	}
+	mv.markDirty("1")
+
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice-on-disk"}}}`)
+	if err := mv.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := mv.DB.Tables["people"].Entries["1"][1].Format(""); got != "alice" {
+		t.Fatalf("expected the unsaved edit to win over the conflicting disk change, got %q", got)
+	}
+	if mv.Mode != MainViewModeAlert {
+		t.Fatalf("expected reload to switch to MainViewModeAlert, got %v", mv.Mode)
+	}
+}
+
+func TestReloadAutoMergesConflictsWhenEnabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice"}}}`)
+
+	mv, err := NewMainView(path, "people")
+	if err != nil {
+		t.Fatalf("NewMainView: %v", err)
+	}
+	mv.markDirty("1")
+	mv.AutoMergeNonConflicting = true
+
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice-on-disk"}}}`)
+	if err := mv.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if mv.Mode == MainViewModeAlert {
+		t.Fatalf("expected AutoMergeNonConflicting to suppress the conflict alert")
+	}
+	if got := mv.DB.Tables["people"].Entries["1"][1].Format(""); got != "alice-on-disk" {
+		t.Fatalf("expected AutoMergeNonConflicting to apply the external edit, got %q", got)
+	}
+	if mv.dirty["people"]["1"] {
+		t.Fatalf("expected AutoMergeNonConflicting to clear the dirty flag it resolved")
+	}
+}
+
+func TestReloadRemovesRowsDeletedOnDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice"}, "2": {"id": "2", "name": "bob"}}}`)
+
+	mv, err := NewMainView(path, "people")
+	if err != nil {
+		t.Fatalf("NewMainView: %v", err)
+	}
+
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice"}}}`)
+	if err := mv.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, ok := mv.DB.Tables["people"].Entries["2"]; ok {
+		t.Fatalf("expected row 2, deleted on disk, to be removed from the in-memory table")
+	}
+	if mv.alert != "" {
+		t.Fatalf("expected no conflict alert for a clean deletion, got %q", mv.alert)
+	}
+}
+
+func TestReloadConflictsOnDirtyRowDeletedOnDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	writeTestJSON(t, path, `{"people": {"1": {"id": "1", "name": "alice"}}}`)
+
+	mv, err := NewMainView(path, "people")
+	if err != nil {
+		t.Fatalf("NewMainView: %v", err)
+	}
+	mv.markDirty("1")
+
+	writeTestJSON(t, path, `{"people": {}}`)
+	if err := mv.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, ok := mv.DB.Tables["people"].Entries["1"]; !ok {
+		t.Fatalf("expected the unsaved edit to keep row 1 rather than silently deleting it")
+	}
+	if mv.Mode != MainViewModeAlert {
+		t.Fatalf("expected reload to alert on a dirty row deleted on disk, got mode %v", mv.Mode)
+	}
+}